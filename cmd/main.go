@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/naspinall/Hive/pkg/config"
+	hivegrpc "github.com/naspinall/Hive/pkg/grpc"
 	"github.com/naspinall/Hive/pkg/middleware"
 
 	"github.com/gorilla/mux"
 	"github.com/naspinall/Hive/pkg/controllers"
 	"github.com/naspinall/Hive/pkg/models"
+	"github.com/naspinall/Hive/pkg/notify"
 )
 
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 
 	cfg := config.LoadConfig()
@@ -22,25 +32,60 @@ func main() {
 		models.WithGorm(dbCfg.Dialect(), dbCfg.ConnectionInfo()),
 		models.WithLogMode(true),
 		models.WithSubscriptions(),
+		models.WithEvents(cfg.EventBuffer),
 		models.WithUsers(cfg.Pepper, cfg.JWTKey),
-		models.WithMeasurements(),
+		models.WithNotifications(map[string]notify.Sender{
+			models.ChannelEmail: notify.NewEmailSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.From, cfg.SMTP.Username, cfg.SMTP.Password),
+			models.ChannelChat:  notify.NewChatSender(nil),
+			models.ChannelHTTP:  notify.NewHTTPSender(nil),
+		}),
+		models.WithEscalations(time.Duration(cfg.EscalationCheck)*time.Second),
+		models.WithAlarms(time.Duration(cfg.IdempotencyWindow)*time.Second),
+		models.WithRules(),
+		models.WithMaintenance(),
+		models.WithHistory(),
+		models.WithHeartbeat(
+			time.Duration(cfg.HeartbeatInterval)*time.Second,
+			time.Duration(cfg.HeartbeatCheck)*time.Second,
+		),
+		models.WithMeasurements(
+			time.Duration(cfg.FutureTolerance)*time.Second,
+			time.Duration(cfg.IdempotencyWindow)*time.Second,
+		),
 		models.WithDevices(),
-		models.WithAlarms(),
 		models.WithRBAC(),
+		models.WithRetention(time.Duration(cfg.RollupInterval)*time.Second),
 	)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer services.Close()
-	services.DestructiveReset()
 	services.AutoMigrate()
 
+	services.Rollup.Start()
+	defer services.Rollup.Stop()
+
+	services.Heartbeat.Start()
+	defer services.Heartbeat.Stop()
+
+	services.Escalator.Start()
+	defer services.Escalator.Stop()
+
 	usersC := controllers.NewUsers(services.User, services.RBAC)
 	devicesC := controllers.NewDevices(services.Device)
 	measurementsC := controllers.NewMeasurements(services.Measurement)
 	alarmsC := controllers.NewAlarms(services.Alarm)
 	subscriptionsC := controllers.NewSubscriptions(services.Subscription)
+	retentionC := controllers.NewRetention(services.Retention)
+	rulesC := controllers.NewRules(services.Rules)
+	notificationsC := controllers.NewNotifications(services.Notifications)
+	escalationsC := controllers.NewEscalations(services.Escalations)
+	maintenanceC := controllers.NewMaintenance(services.Maintenance)
+	historyC := controllers.NewHistory(services.History)
+	importsC := controllers.NewImports(services.Device, services.Measurement)
+	influxC := controllers.NewInflux(services.Device, services.Measurement)
+	prometheusC := controllers.NewPrometheus(services.Device, services.Measurement, cfg.DeviceLabel)
 	userM := middleware.NewUsersMiddleware(services.User)
 	auth := userM.JWTAuth()
 
@@ -54,10 +99,15 @@ func main() {
 	d.Use(auth)
 	d.HandleFunc("/", devicesC.GetMany).Methods("GET")
 	d.HandleFunc("/", devicesC.Create).Methods("POST")
+	d.HandleFunc("/state", measurementsC.StateMany).Methods("GET")
 	d.HandleFunc("/{id}/", devicesC.Delete).Methods("DELETE")
 	d.HandleFunc("/{id}", devicesC.Get).Methods("GET")
 	d.HandleFunc("/{id}/measurements", measurementsC.Create).Methods("POST")
 	d.HandleFunc("/{id}/measurements", measurementsC.GetByDevice).Methods("GET")
+	d.HandleFunc("/{id}/measurements/aggregate", measurementsC.Aggregate).Methods("GET")
+	d.HandleFunc("/{id}/measurements/batch", measurementsC.CreateBatch).Methods("POST")
+	d.HandleFunc("/{id}/measurements/export", measurementsC.Export).Methods("GET")
+	d.HandleFunc("/{id}/state", measurementsC.State).Methods("GET")
 	d.HandleFunc("/{id}/alarms", alarmsC.Create).Methods("POST")
 	d.HandleFunc("/{id}/alarms", alarmsC.GetByDevice).Methods("GET")
 	d.HandleFunc("/{id}/subscribe/", subscriptionsC.Create).Methods("POST")
@@ -76,15 +126,25 @@ func main() {
 	//Measurement CRUD
 	m := api.PathPrefix("/measurements").Subrouter()
 	m.Use(auth)
+	m.HandleFunc("/batch", measurementsC.CreateBatchMany).Methods("POST")
+	m.HandleFunc("/export", measurementsC.ExportMany).Methods("GET")
+	m.HandleFunc("/import", importsC.Measurements).Methods("POST")
 	m.HandleFunc("/{id}/", measurementsC.Delete).Methods("DELETE")
 	m.HandleFunc("/{id}/", measurementsC.Get).Methods("GET")
 
 	//Alarm CRUD
 	a := api.PathPrefix("/alarms").Subrouter()
 	a.Use(auth)
+	a.HandleFunc("/summary", alarmsC.Summary).Methods("GET")
 	a.HandleFunc("/{id}/", alarmsC.Delete).Methods("DELETE")
-	a.HandleFunc("/{id}/", alarmsC.Create).Methods("POST")
+	a.HandleFunc("/{id}/", alarmsC.Update).Methods("PUT")
 	a.HandleFunc("/{id}/", alarmsC.Get).Methods("GET")
+	a.HandleFunc("/{id}/acknowledge", alarmsC.Acknowledge).Methods("POST")
+	a.HandleFunc("/{id}/clear", alarmsC.Clear).Methods("POST")
+	a.HandleFunc("/{id}/comments", historyC.GetComments).Methods("GET")
+	a.HandleFunc("/{id}/comments", historyC.CreateComment).Methods("POST")
+	a.HandleFunc("/{id}/comments/{commentId}", historyC.DeleteComment).Methods("DELETE")
+	a.HandleFunc("/{id}/history", historyC.GetHistory).Methods("GET")
 	a.HandleFunc("/", alarmsC.GetMany).Methods("GET")
 
 	// Subscriptions CRUD
@@ -94,7 +154,106 @@ func main() {
 	s.HandleFunc("/{id}/", subscriptionsC.Delete).Methods("DELETE")
 	s.HandleFunc("/", subscriptionsC.GetMany).Methods("GET")
 
+	// InfluxDB line protocol writes
+	iw := api.PathPrefix("/write").Subrouter()
+	iw.Use(auth)
+	iw.HandleFunc("", influxC.Write).Methods("POST")
+
+	// Prometheus remote write
+	pw := api.PathPrefix("/prometheus").Subrouter()
+	pw.Use(auth)
+	pw.HandleFunc("/write", prometheusC.Write).Methods("POST")
+
+	// Retention Policy CRUD
+	rp := api.PathPrefix("/retention").Subrouter()
+	rp.Use(auth)
+	rp.HandleFunc("/", retentionC.GetMany).Methods("GET")
+	rp.HandleFunc("/", retentionC.Create).Methods("POST")
+	rp.HandleFunc("/{id}/", retentionC.Get).Methods("GET")
+	rp.HandleFunc("/{id}/", retentionC.Update).Methods("PUT")
+	rp.HandleFunc("/{id}/", retentionC.Delete).Methods("DELETE")
+
+	// Alarm Rule CRUD
+	ar := api.PathPrefix("/rules").Subrouter()
+	ar.Use(auth)
+	ar.HandleFunc("/", rulesC.GetMany).Methods("GET")
+	ar.HandleFunc("/", rulesC.Create).Methods("POST")
+	ar.HandleFunc("/{id}/", rulesC.Get).Methods("GET")
+	ar.HandleFunc("/{id}/", rulesC.Update).Methods("PUT")
+	ar.HandleFunc("/{id}/", rulesC.Delete).Methods("DELETE")
+
+	// Notification Channels and Routes
+	n := api.PathPrefix("/notifications").Subrouter()
+	n.Use(auth)
+	n.HandleFunc("/channels/", notificationsC.GetChannels).Methods("GET")
+	n.HandleFunc("/channels/", notificationsC.CreateChannel).Methods("POST")
+	n.HandleFunc("/channels/{id}/", notificationsC.GetChannel).Methods("GET")
+	n.HandleFunc("/channels/{id}/", notificationsC.UpdateChannel).Methods("PUT")
+	n.HandleFunc("/channels/{id}/", notificationsC.DeleteChannel).Methods("DELETE")
+	n.HandleFunc("/routes/", notificationsC.GetRoutes).Methods("GET")
+	n.HandleFunc("/routes/", notificationsC.CreateRoute).Methods("POST")
+	n.HandleFunc("/routes/{id}/", notificationsC.DeleteRoute).Methods("DELETE")
+
+	// Escalation Policy CRUD
+	ep := api.PathPrefix("/escalations").Subrouter()
+	ep.Use(auth)
+	ep.HandleFunc("/", escalationsC.GetMany).Methods("GET")
+	ep.HandleFunc("/", escalationsC.Create).Methods("POST")
+	ep.HandleFunc("/{id}/", escalationsC.Get).Methods("GET")
+	ep.HandleFunc("/{id}/", escalationsC.Update).Methods("PUT")
+	ep.HandleFunc("/{id}/", escalationsC.Delete).Methods("DELETE")
+
+	// Maintenance Window CRUD
+	mw := api.PathPrefix("/maintenance").Subrouter()
+	mw.Use(auth)
+	mw.HandleFunc("/", maintenanceC.GetMany).Methods("GET")
+	mw.HandleFunc("/", maintenanceC.Create).Methods("POST")
+	mw.HandleFunc("/{id}/", maintenanceC.Get).Methods("GET")
+	mw.HandleFunc("/{id}/", maintenanceC.Update).Methods("PUT")
+	mw.HandleFunc("/{id}/", maintenanceC.Delete).Methods("DELETE")
+
 	//Roles CRUD
-	log.Println(fmt.Sprintf("Listening on port %d", cfg.Port))
-	http.ListenAndServe(":3001", r)
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Port), Handler: r}
+	grpcSrv := hivegrpc.NewServer(services)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		log.Println(fmt.Sprintf("Listening on port %d", cfg.Port))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	go func() {
+		log.Println(fmt.Sprintf("gRPC listening on port %d", cfg.GRPCPort))
+		if err := grpcSrv.Serve(lis); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// Wait for a signal, then let in flight requests on both listeners finish.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println(err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcSrv.Stop()
+	}
 }
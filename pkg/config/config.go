@@ -16,12 +16,31 @@ type PostgresConfig struct {
 	Name     string `json:"name"`
 }
 
+// SMTPConfig is the mail server email notifications are sent through.
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	From     string `json:"from"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
 type Config struct {
-	Port     int            `json:"port"`
-	Env      string         `json:"env"`
-	Pepper   string         `json:"pepper"`
-	JWTKey   string         `json:"jwtKey"`
-	Database PostgresConfig `json:"database"`
+	Port              int            `json:"port"`
+	GRPCPort          int            `json:"grpcPort"`
+	Env               string         `json:"env"`
+	Pepper            string         `json:"pepper"`
+	JWTKey            string         `json:"jwtKey"`
+	RollupInterval    int            `json:"rollupInterval"`
+	FutureTolerance   int            `json:"futureTolerance"`
+	IdempotencyWindow int            `json:"idempotencyWindow"`
+	DeviceLabel       string         `json:"deviceLabel"`
+	EventBuffer       int            `json:"eventBuffer"`
+	HeartbeatInterval int            `json:"heartbeatInterval"`
+	HeartbeatCheck    int            `json:"heartbeatCheck"`
+	EscalationCheck   int            `json:"escalationCheck"`
+	SMTP              SMTPConfig     `json:"smtp"`
+	Database          PostgresConfig `json:"database"`
 }
 
 func (c PostgresConfig) Dialect() string {
@@ -46,17 +65,35 @@ func DefaultPostgresConfig() PostgresConfig {
 	}
 }
 
+func DefaultSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		Host: "localhost",
+		Port: 25,
+		From: "hive@localhost",
+	}
+}
+
 func (c Config) IsProd() bool {
 	return c.Env == "production"
 }
 
 func DefaultConfig() Config {
 	return Config{
-		Port:     3001,
-		Env:      "development",
-		Pepper:   "salt-and-pepper-is-delicious",
-		JWTKey:   "jwt-make-life-easy",
-		Database: DefaultPostgresConfig(),
+		Port:              3001,
+		GRPCPort:          3002,
+		Env:               "development",
+		Pepper:            "salt-and-pepper-is-delicious",
+		JWTKey:            "jwt-make-life-easy",
+		RollupInterval:    300,
+		FutureTolerance:   300,
+		IdempotencyWindow: 86400,
+		DeviceLabel:       "hive_device",
+		EventBuffer:       256,
+		HeartbeatInterval: 300,
+		HeartbeatCheck:    60,
+		EscalationCheck:   30,
+		SMTP:              DefaultSMTPConfig(),
+		Database:          DefaultPostgresConfig(),
 	}
 }
 
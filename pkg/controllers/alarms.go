@@ -2,8 +2,10 @@ package controllers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/naspinall/Hive/pkg/models"
@@ -36,13 +38,16 @@ func (a *Alarms) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	alarm.DeviceID = uint(id)
 
-	if err := a.as.Create(&alarm, r.Context()); err != nil {
+	// Retries with the same key get back the alarm created first.
+	ctx := models.WithIdempotencyKey(r.Context(), r.Header.Get("Idempotency-Key"))
+	if err := a.as.Create(&alarm, ctx); err != nil {
 		ProcessError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&alarm)
 }
 
 func (a *Alarms) Delete(w http.ResponseWriter, r *http.Request) {
@@ -104,21 +109,207 @@ func (a *Alarms) GetByDevice(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetMany lists a page of the alarms matching the query parameters, sorted by
+// sort in the given order. The next page is linked in the Link header.
 func (a *Alarms) GetMany(w http.ResponseWriter, r *http.Request) {
-	var err error
-	var count int64 = 100
+	query, err := parseAlarmQuery(r)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	page, err := a.as.Query(query, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	setNextLink(w, r, page.Next)
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&page.Alarms)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+// Summary counts the alarms matching the same filters as GetMany, by
+// severity and by status.
+func (a *Alarms) Summary(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAlarmQuery(r)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	summary, err := a.as.Summary(query, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(summary)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func parseAlarmQuery(r *http.Request) (*models.AlarmQuery, error) {
 	q := r.URL.Query()
-	cq, ok := q["count"]
-	if ok {
-		count, err = strconv.ParseInt(cq[0], 10, 64)
+	query := &models.AlarmQuery{
+		PageQuery: models.PageQuery{Cursor: q.Get("next")},
+		Group:     q.Get("group"),
+		Type:      q.Get("type"),
+		Status:    q.Get("status"),
+		Severity:  q.Get("severity"),
+		Sort:      q.Get("sort"),
+		Order:     q.Get("order"),
+	}
+
+	if v := q.Get("count"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil || count < 1 {
+			return nil, models.ErrInvalidLimit
+		}
+		query.Limit = count
+	}
+
+	if v := q.Get("deviceId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, models.ErrInvalidID
+		}
+		query.DeviceID = uint(id)
+	}
+
+	if v := q.Get("suppressed"); v != "" {
+		suppressed, err := strconv.ParseBool(v)
 		if err != nil {
-			ProcessError(w, models.ErrInvalidID)
+			return nil, models.ErrInvalidSuppressed
+		}
+		query.Suppressed = &suppressed
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseTime(q.Get("createdFrom")); err != nil {
+		return nil, err
+	}
+	if query.CreatedTo, err = parseTime(q.Get("createdTo")); err != nil {
+		return nil, err
+	}
+	if query.UpdatedFrom, err = parseTime(q.Get("updatedFrom")); err != nil {
+		return nil, err
+	}
+	if query.UpdatedTo, err = parseTime(q.Get("updatedTo")); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// Reads an optional RFC3339 time.
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, models.ErrInvalidTimeRange
+	}
+	return &t, nil
+}
+
+// alarmUpdate holds the fields of an alarm a user may change, the rest are
+// kept by the alarm's lifecycle.
+type alarmUpdate struct {
+	Severity *string `json:"severity"`
+	Status   *string `json:"status"`
+	Comment  string  `json:"comment"`
+}
+
+// Update changes the severity or status of an alarm, whichever are given in
+// the body. A status change is made as a transition, with the optional
+// comment, so it is checked and recorded like an acknowledge or clear.
+func (a *Alarms) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	var req alarmUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		BadRequest(w, err)
+		return
+	}
+
+	alarm, err := a.as.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	if req.Severity != nil && *req.Severity != alarm.Severity {
+		alarm.Severity = *req.Severity
+		if err := a.as.Update(alarm, r.Context()); err != nil {
+			ProcessError(w, err)
 			return
 		}
 	}
-	alarms, err := a.as.Many(int(count), r.Context())
+	if req.Status != nil && *req.Status != alarm.Status {
+		if alarm, err = a.as.Transition(uint(id), *req.Status, req.Comment, r.Context()); err != nil {
+			ProcessError(w, err)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(&alarms)
+	err = json.NewEncoder(w).Encode(alarm)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+// Acknowledge moves an active alarm to ACKNOWLEDGED.
+func (a *Alarms) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	a.transition(w, r, models.AlarmAcknowledged)
+}
+
+// Clear moves an active or acknowledged alarm to CLEARED.
+func (a *Alarms) Clear(w http.ResponseWriter, r *http.Request) {
+	a.transition(w, r, models.AlarmCleared)
+}
+
+type transitionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// The body, holding an optional comment, may be left empty.
+func (a *Alarms) transition(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	var req transitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		BadRequest(w, err)
+		return
+	}
+
+	alarm, err := a.as.Transition(uint(id), status, req.Comment, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(alarm)
 
 	if err != nil {
 		ProcessError(w, err)
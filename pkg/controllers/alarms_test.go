@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+// stubAlarms serves a single alarm and records the changes made to it.
+type stubAlarms struct {
+	models.AlarmService
+	alarm       models.Alarm
+	updates     []models.Alarm
+	transitions []string
+}
+
+func (s *stubAlarms) ByID(id uint, ctx context.Context) (*models.Alarm, error) {
+	alarm := s.alarm
+	return &alarm, nil
+}
+
+func (s *stubAlarms) Update(alarm *models.Alarm, ctx context.Context) error {
+	s.updates = append(s.updates, *alarm)
+	s.alarm = *alarm
+	return nil
+}
+
+func (s *stubAlarms) Transition(id uint, status, comment string, ctx context.Context) (*models.Alarm, error) {
+	s.transitions = append(s.transitions, status+":"+comment)
+	s.alarm.Status = status
+	alarm := s.alarm
+	return &alarm, nil
+}
+
+func TestAlarmsUpdate(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        string
+		severity    string
+		transitions []string
+	}{
+		{"severity", `{"severity":"MINOR"}`, "MINOR", nil},
+		{"status", `{"status":"ACKNOWLEDGED","comment":"on it"}`, "", []string{"ACKNOWLEDGED:on it"}},
+		{"both", `{"severity":"MINOR","status":"CLEARED"}`, "MINOR", []string{"CLEARED:"}},
+		{"unchanged", `{"severity":"MAJOR","status":"ACTIVE"}`, "", nil},
+		// Fields outside the update are ignored rather than written.
+		{"other fields", `{"DeviceID":9,"Count":100,"Suppressed":true,"ClearedBy":1,"Type":"x"}`, "", nil},
+	}
+	for _, c := range cases {
+		alarms := &stubAlarms{alarm: models.Alarm{
+			Model:    gorm.Model{ID: 4},
+			Type:     "temperature",
+			Status:   models.AlarmActive,
+			Severity: "MAJOR",
+			DeviceID: 1,
+			Count:    2,
+		}}
+		r := mux.SetURLVars(httptest.NewRequest("PUT", "/api/alarms/4/", strings.NewReader(c.body)), map[string]string{"id": "4"})
+		w := httptest.NewRecorder()
+		NewAlarms(alarms).Update(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status %d", c.name, w.Code)
+			continue
+		}
+		if c.severity == "" && len(alarms.updates) != 0 {
+			t.Errorf("%s: updated %+v", c.name, alarms.updates)
+		}
+		if c.severity != "" && (len(alarms.updates) != 1 || alarms.updates[0].Severity != c.severity) {
+			t.Errorf("%s: updates %+v, want severity %s", c.name, alarms.updates, c.severity)
+		}
+		if strings.Join(alarms.transitions, ",") != strings.Join(c.transitions, ",") {
+			t.Errorf("%s: transitions %v, want %v", c.name, alarms.transitions, c.transitions)
+		}
+
+		var got models.Alarm
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.DeviceID != 1 || got.Count != 2 || got.Suppressed || got.Type != "temperature" {
+			t.Errorf("%s: responded with %+v", c.name, got)
+		}
+	}
+}
+
+func TestAlarmsUpdateBadBody(t *testing.T) {
+	alarms := &stubAlarms{}
+	r := mux.SetURLVars(httptest.NewRequest("PUT", "/api/alarms/4/", strings.NewReader(`{"severity":`)), map[string]string{"id": "4"})
+	w := httptest.NewRecorder()
+	NewAlarms(alarms).Update(w, r)
+	if w.Code != http.StatusBadRequest || len(alarms.updates) != 0 || len(alarms.transitions) != 0 {
+		t.Errorf("status %d, updates %v, transitions %v", w.Code, alarms.updates, alarms.transitions)
+	}
+}
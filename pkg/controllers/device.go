@@ -76,6 +76,8 @@ func (d *Devices) Get(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetMany lists up to count devices, optionally only those with the given
+// connectivity status. The cursor for the next page is sent in a Link header.
 func (d *Devices) GetMany(w http.ResponseWriter, r *http.Request) {
 	var err error
 	var count int64 = 100
@@ -88,13 +90,24 @@ func (d *Devices) GetMany(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	devices, err := d.ds.Many(int(count), r.Context())
+
+	status := q.Get("status")
+	if status != "" && status != models.DeviceOnline && status != models.DeviceOffline {
+		ProcessError(w, models.ErrInvalidDeviceStatus)
+		return
+	}
+
+	page, err := d.ds.Query(&models.DeviceQuery{
+		PageQuery: models.PageQuery{Limit: int(count), Cursor: q.Get("next")},
+		Status:    status,
+	}, r.Context())
 	if err != nil {
 		ProcessError(w, err)
 		return
 	}
+	setNextLink(w, r, page.Next)
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(&devices)
+	err = json.NewEncoder(w).Encode(&page.Devices)
 
 	if err != nil {
 		ProcessError(w, err)
@@ -32,6 +32,10 @@ func BadRequest(w http.ResponseWriter, err error) {
 	http.Error(w, err.Error(), http.StatusBadRequest)
 }
 
+func Conflict(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusConflict)
+}
+
 func ProcessError(w http.ResponseWriter, err error) {
 	if e, ok := err.(models.ErrorNotFound); ok {
 		NotFound(w, e)
@@ -39,6 +43,8 @@ func ProcessError(w http.ResponseWriter, err error) {
 		Unauthorized(w, e)
 	} else if e, ok := err.(models.ErrorBadRequest); ok {
 		BadRequest(w, e)
+	} else if e, ok := err.(models.ErrorConflict); ok {
+		Conflict(w, e)
 	} else {
 		InternalServerError(w, err)
 	}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Escalations struct {
+	es models.EscalationService
+}
+
+func NewEscalations(es models.EscalationService) *Escalations {
+	return &Escalations{
+		es: es,
+	}
+}
+
+func (ec *Escalations) Create(w http.ResponseWriter, r *http.Request) {
+	var policy models.EscalationPolicy
+	err := json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := ec.es.Create(&policy, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&policy)
+}
+
+func (ec *Escalations) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	policy, err := ec.es.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(policy); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	policy.ID = uint(id)
+
+	if err := ec.es.Update(policy, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policy)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (ec *Escalations) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := ec.es.Delete(uint(id), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ec *Escalations) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	policy, err := ec.es.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policy)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (ec *Escalations) GetMany(w http.ResponseWriter, r *http.Request) {
+	policies, err := ec.es.Many(r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policies)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	// Rows written between flushes to the client.
+	exportFlushEvery = 500
+)
+
+var csvHeader = []string{"id", "device_id", "type", "value", "unit", "observed_at", "created_at"}
+
+// Export streams the measurements of the device in the path.
+func (m *Measurements) Export(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	m.export(w, r, []uint{uint(id)})
+}
+
+// ExportMany streams the measurements of the devices in the ids query
+// parameter, or of every device when it is missing.
+func (m *Measurements) ExportMany(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	m.export(w, r, ids)
+}
+
+func (m *Measurements) export(w http.ResponseWriter, r *http.Request, ids []uint) {
+	format, err := exportFormat(r)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	query := &models.ExportQuery{
+		DeviceIDs: ids,
+		Type:      r.URL.Query().Get("type"),
+	}
+	if query.From, query.To, err = parseTimeRange(r); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	cw := csv.NewWriter(w)
+	enc := json.NewEncoder(w)
+	rows := 0
+
+	// Headers are only sent with the first row, so errors raised before any
+	// data is read can still be returned with a proper status.
+	start := func() {
+		if format == formatCSV {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", `attachment; filename="measurements.csv"`)
+			w.WriteHeader(http.StatusOK)
+			cw.Write(csvHeader)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err = m.ms.Export(query, r.Context(), func(measurement *models.Measurement) error {
+		if rows == 0 {
+			start()
+		}
+		rows++
+
+		if format == formatCSV {
+			cw.Write(csvRecord(measurement))
+		} else if err := enc.Encode(measurement); err != nil {
+			return err
+		}
+
+		if rows%exportFlushEvery == 0 {
+			cw.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return cw.Error()
+	})
+
+	if err != nil && rows == 0 {
+		ProcessError(w, err)
+		return
+	}
+	if err != nil {
+		// Too late to change the status, the client sees a truncated body.
+		log.Println(err)
+		return
+	}
+	if rows == 0 {
+		start()
+	}
+	cw.Flush()
+}
+
+func csvRecord(m *models.Measurement) []string {
+	return []string{
+		strconv.FormatUint(uint64(m.ID), 10),
+		strconv.FormatUint(uint64(m.DeviceID), 10),
+		m.Type,
+		strconv.FormatFloat(m.Value, 'g', -1, 64),
+		m.Unit,
+		m.ObservedAt.UTC().Format(time.RFC3339Nano),
+		m.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// The format query parameter takes priority over the Accept header.
+func exportFormat(r *http.Request) (string, error) {
+	switch r.URL.Query().Get("format") {
+	case formatCSV:
+		return formatCSV, nil
+	case formatNDJSON:
+		return formatNDJSON, nil
+	case "":
+	default:
+		return "", models.ErrInvalidFormat
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "application/x-ndjson") || strings.Contains(accept, "application/ndjson") {
+		return formatNDJSON, nil
+	}
+	return formatCSV, nil
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type History struct {
+	hs models.HistoryService
+}
+
+func NewHistory(hs models.HistoryService) *History {
+	return &History{
+		hs: hs,
+	}
+}
+
+func (hc *History) GetComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	comments, err := hc.hs.Comments(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(comments)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+// CreateComment adds a comment to the alarm, written by the requesting user.
+func (hc *History) CreateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	var comment models.AlarmComment
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		BadRequest(w, err)
+		return
+	}
+	comment.AlarmID = uint(id)
+
+	if err := hc.hs.CreateComment(&comment, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&comment)
+}
+
+func (hc *History) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+	commentID, err := strconv.ParseUint(vars["commentId"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := hc.hs.DeleteComment(uint(id), uint(commentID), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetHistory lists every change to the alarm's state, oldest first.
+func (hc *History) GetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	events, err := hc.hs.History(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(events)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Imports struct {
+	mi *models.MeasurementImporter
+}
+
+func NewImports(ds models.DeviceService, ms models.MeasurementService) *Imports {
+	return &Imports{
+		mi: models.NewMeasurementImporter(ds, ms),
+	}
+}
+
+// Measurements imports a CSV body of historian measurements. With dryRun set
+// every row is validated but nothing is written.
+func (i *Imports) Measurements(w http.ResponseWriter, r *http.Request) {
+	var err error
+	dryRun := false
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+	}
+
+	report, err := i.mi.Import(r.Body, dryRun, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/naspinall/Hive/pkg/lineprotocol"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Influx struct {
+	lw *models.LineProtocolWriter
+}
+
+func NewInflux(ds models.DeviceService, ms models.MeasurementService) *Influx {
+	return &Influx{
+		lw: models.NewLineProtocolWriter(ds, ms),
+	}
+}
+
+// Write accepts an InfluxDB line protocol body, compatible with the /write
+// endpoint of InfluxDB 1.x clients such as Telegraf.
+func (i *Influx) Write(w http.ResponseWriter, r *http.Request) {
+	precision, ok := lineprotocol.Precisions[r.URL.Query().Get("precision")]
+	if !ok {
+		ProcessError(w, models.ErrInvalidPrecision)
+		return
+	}
+
+	report, err := i.lw.Write(r.Body, precision, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if report.Failed == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Like InfluxDB, a partial write is a bad request, the report says which
+	// lines were rejected.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Maintenance struct {
+	ms models.MaintenanceService
+}
+
+func NewMaintenance(ms models.MaintenanceService) *Maintenance {
+	return &Maintenance{
+		ms: ms,
+	}
+}
+
+func (mc *Maintenance) Create(w http.ResponseWriter, r *http.Request) {
+	var window models.MaintenanceWindow
+	err := json.NewDecoder(r.Body).Decode(&window)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := mc.ms.Create(&window, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&window)
+}
+
+func (mc *Maintenance) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	window, err := mc.ms.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(window); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	window.ID = uint(id)
+
+	if err := mc.ms.Update(window, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(window)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (mc *Maintenance) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := mc.ms.Delete(uint(id), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (mc *Maintenance) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	window, err := mc.ms.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(window)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (mc *Maintenance) GetMany(w http.ResponseWriter, r *http.Request) {
+	windows, err := mc.ms.Many(r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(windows)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
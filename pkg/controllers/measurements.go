@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/naspinall/Hive/pkg/models"
@@ -19,10 +21,26 @@ func NewMeasurements(ms models.MeasurementService) *Measurements {
 	}
 }
 
+// Create adds a measurement to the device in the path. A SenML pack may hold
+// several records, so it is created as a batch.
 func (m *Measurements) Create(w http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if isSenML(r) {
+		measurements, err := decodeSenML(r, uint(id))
+		if err != nil {
+			ProcessError(w, err)
+			return
+		}
+		m.createBatch(w, r, measurements)
+		return
+	}
 
 	var measurement models.Measurement
 	err = json.NewDecoder(r.Body).Decode(&measurement)
@@ -32,13 +50,80 @@ func (m *Measurements) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	measurement.DeviceID = uint(id)
 
-	if err := m.ms.Create(&measurement, r.Context()); err != nil {
+	// Retries with the same key get back the measurement created first.
+	ctx := models.WithIdempotencyKey(r.Context(), r.Header.Get("Idempotency-Key"))
+	if err := m.ms.Create(&measurement, ctx); err != nil {
 		ProcessError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&measurement)
+}
+
+// CreateBatch inserts a batch of measurements for the device in the path.
+func (m *Measurements) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if isSenML(r) {
+		measurements, err := decodeSenML(r, uint(id))
+		if err != nil {
+			ProcessError(w, err)
+			return
+		}
+		m.createBatch(w, r, measurements)
+		return
+	}
+
+	var measurements []*models.Measurement
+	if err := json.NewDecoder(r.Body).Decode(&measurements); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	for _, measurement := range measurements {
+		measurement.DeviceID = uint(id)
+	}
+
+	m.createBatch(w, r, measurements)
+}
+
+// CreateBatchMany inserts a batch of measurements, each naming its own device.
+func (m *Measurements) CreateBatchMany(w http.ResponseWriter, r *http.Request) {
+	var measurements []*models.Measurement
+	if err := json.NewDecoder(r.Body).Decode(&measurements); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	m.createBatch(w, r, measurements)
+}
+
+func (m *Measurements) createBatch(w http.ResponseWriter, r *http.Request, measurements []*models.Measurement) {
+	if len(measurements) == 0 {
+		ProcessError(w, models.ErrBatchEmpty)
+		return
+	}
+
+	ctx := models.WithIdempotencyKey(r.Context(), r.Header.Get("Idempotency-Key"))
+	results, err := m.ms.CreateBatch(measurements, ctx)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(&results)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
 }
 
 func (m *Measurements) Delete(w http.ResponseWriter, r *http.Request) {
@@ -86,16 +171,180 @@ func (m *Measurements) GetByDevice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	measurements, err := m.ms.ByDevice(uint(id), r.Context())
+	query, err := parseMeasurementQuery(r)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	page, err := m.ms.ByDevice(uint(id), query, r.Context())
 	if err != nil {
 		ProcessError(w, err)
 		return
 	}
+
+	if contentType := senmlFormat(r); contentType != "" {
+		if err := writeSenML(w, r, contentType, page); err != nil {
+			ProcessError(w, err)
+		}
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(&measurements)
+	err = json.NewEncoder(w).Encode(&page)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
 
+func (m *Measurements) Aggregate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	q := r.URL.Query()
+	query := &models.AggregateQuery{
+		Type:     q.Get("type"),
+		Bucket:   q.Get("bucket"),
+		Function: q.Get("fn"),
+		Fill:     q.Get("fill"),
+	}
+	if query.From, query.To, err = parseTimeRange(r); err != nil {
 		ProcessError(w, err)
 		return
 	}
+
+	buckets, err := m.ms.Aggregate(uint(id), query, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&buckets)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+// State returns the latest value of every measurement type for a device.
+func (m *Measurements) State(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	states, err := m.ms.State([]uint{uint(id)}, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&states)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+// StateMany returns the latest values for each device in the ids query
+// parameter, keyed by device ID.
+func (m *Measurements) StateMany(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil || len(ids) == 0 {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	states, err := m.ms.State(ids, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	byDevice := make(map[uint][]models.MeasurementState, len(ids))
+	for _, id := range ids {
+		byDevice[id] = []models.MeasurementState{}
+	}
+	for _, state := range states {
+		byDevice[state.DeviceID] = append(byDevice[state.DeviceID], state)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(&byDevice)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+// Reads the from, to, type, order, limit and next query parameters.
+func parseMeasurementQuery(r *http.Request) (*models.MeasurementQuery, error) {
+	q := r.URL.Query()
+	query := &models.MeasurementQuery{
+		Type:   q.Get("type"),
+		Order:  q.Get("order"),
+		Cursor: q.Get("next"),
+	}
+
+	var err error
+	if query.From, query.To, err = parseTimeRange(r); err != nil {
+		return nil, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return nil, models.ErrInvalidLimit
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+// Reads the optional RFC3339 from and to query parameters.
+func parseTimeRange(r *http.Request) (from, to *time.Time, err error) {
+	q := r.URL.Query()
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, models.ErrInvalidTimeRange
+		}
+		from = &t
+	}
+
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, models.ErrInvalidTimeRange
+		}
+		to = &t
+	}
+	return from, to, nil
+}
+
+// Reads a comma separated list of IDs.
+func parseIDs(value string) ([]uint, error) {
+	var ids []uint
+	for _, field := range strings.Split(value, ",") {
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, models.ErrInvalidID
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
 }
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Notifications struct {
+	ns models.NotificationService
+}
+
+func NewNotifications(ns models.NotificationService) *Notifications {
+	return &Notifications{
+		ns: ns,
+	}
+}
+
+func (n *Notifications) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	var channel models.NotificationChannel
+	err := json.NewDecoder(r.Body).Decode(&channel)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := n.ns.CreateChannel(&channel, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&channel)
+}
+
+func (n *Notifications) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	channel, err := n.ns.ChannelByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(channel); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	channel.ID = uint(id)
+
+	if err := n.ns.UpdateChannel(channel, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(channel)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (n *Notifications) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := n.ns.DeleteChannel(uint(id), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Notifications) GetChannel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	channel, err := n.ns.ChannelByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(channel)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (n *Notifications) GetChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := n.ns.Channels(r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(channels)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (n *Notifications) CreateRoute(w http.ResponseWriter, r *http.Request) {
+	var route models.NotificationRoute
+	err := json.NewDecoder(r.Body).Decode(&route)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := n.ns.CreateRoute(&route, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&route)
+}
+
+func (n *Notifications) DeleteRoute(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := n.ns.DeleteRoute(uint(id), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Notifications) GetRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, err := n.ns.Routes(r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(routes)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
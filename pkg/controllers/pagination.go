@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// setNextLink sends the cursor for the next page in a Link header, when there
+// is a next page.
+func setNextLink(w http.ResponseWriter, r *http.Request, next string) {
+	if next == "" {
+		return
+	}
+	q := r.URL.Query()
+	q.Set("next", next)
+	link := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, link.String()))
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/naspinall/Hive/pkg/models"
+	"github.com/naspinall/Hive/pkg/remotewrite"
+)
+
+// Remote write bodies are snappy compressed, so the decoded size is capped
+// separately when the body is decompressed.
+const maxRemoteWriteBody = 8 * 1024 * 1024
+
+type Prometheus struct {
+	pw *models.PrometheusWriter
+}
+
+func NewPrometheus(ds models.DeviceService, ms models.MeasurementService, deviceLabel string) *Prometheus {
+	return &Prometheus{
+		pw: models.NewPrometheusWriter(ds, ms, deviceLabel),
+	}
+}
+
+// Write is a Prometheus remote write receiver.
+func (p *Prometheus) Write(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRemoteWriteBody))
+	if err != nil {
+		BadRequest(w, err)
+		return
+	}
+
+	req, err := remotewrite.Decode(body)
+	if err != nil {
+		BadRequest(w, err)
+		return
+	}
+
+	report, err := p.pw.Write(req, r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if report.Failed == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Prometheus retries server errors but drops a request on a client error,
+	// rejected series would only be rejected again.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(report)
+}
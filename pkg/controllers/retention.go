@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Retention struct {
+	rs models.RetentionService
+}
+
+func NewRetention(rs models.RetentionService) *Retention {
+	return &Retention{
+		rs: rs,
+	}
+}
+
+func (rt *Retention) Create(w http.ResponseWriter, r *http.Request) {
+	var policy models.RetentionPolicy
+	err := json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := rt.rs.Create(&policy, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&policy)
+}
+
+func (rt *Retention) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	policy, err := rt.rs.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(policy); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	policy.ID = uint(id)
+
+	if err := rt.rs.Update(policy, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policy)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (rt *Retention) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := rt.rs.Delete(uint(id), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rt *Retention) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	policy, err := rt.rs.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policy)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (rt *Retention) GetMany(w http.ResponseWriter, r *http.Request) {
+	policies, err := rt.rs.Many(r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(policies)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+type Rules struct {
+	rs models.RuleService
+}
+
+func NewRules(rs models.RuleService) *Rules {
+	return &Rules{
+		rs: rs,
+	}
+}
+
+func (rc *Rules) Create(w http.ResponseWriter, r *http.Request) {
+	var rule models.AlarmRule
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := rc.rs.Create(&rule, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(&rule)
+}
+
+func (rc *Rules) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	rule, err := rc.rs.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	rule.ID = uint(id)
+
+	if err := rc.rs.Update(rule, r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rule)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (rc *Rules) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	if err := rc.rs.Delete(uint(id), r.Context()); err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *Rules) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		ProcessError(w, models.ErrInvalidID)
+		return
+	}
+
+	rule, err := rc.rs.ByID(uint(id), r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rule)
+
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
+
+func (rc *Rules) GetMany(w http.ResponseWriter, r *http.Request) {
+	rules, err := rc.rs.Many(r.Context())
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		ProcessError(w, err)
+		return
+	}
+}
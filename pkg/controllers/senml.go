@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/naspinall/Hive/pkg/models"
+	"github.com/naspinall/Hive/pkg/senml"
+)
+
+// isSenML reports whether the request body is a SenML pack.
+func isSenML(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == senml.ContentTypeJSON || mediaType == senml.ContentTypeCBOR
+}
+
+// decodeSenML reads a SenML pack into measurements for a device. The name of
+// each record less its base name is the measurement type, so a device can
+// name itself with the base name.
+func decodeSenML(r *http.Request, deviceID uint) ([]*models.Measurement, error) {
+	var pack senml.Pack
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == senml.ContentTypeCBOR {
+		pack, err = senml.DecodeCBOR(r.Body)
+	} else {
+		pack, err = senml.DecodeJSON(r.Body)
+	}
+	if err != nil {
+		return nil, models.ErrorBadRequest(err.Error())
+	}
+
+	records, err := senml.Resolve(pack, time.Now())
+	if err != nil {
+		return nil, models.ErrorBadRequest(err.Error())
+	}
+
+	measurements := make([]*models.Measurement, 0, len(records))
+	for _, record := range records {
+		measurementType := strings.TrimPrefix(record.Name, record.BaseName)
+		if measurementType == "" {
+			measurementType = record.Name
+		}
+		measurements = append(measurements, &models.Measurement{
+			DeviceID:   deviceID,
+			Type:       measurementType,
+			Value:      record.Value,
+			Unit:       record.Unit,
+			ObservedAt: record.Time,
+		})
+	}
+	return measurements, nil
+}
+
+// senmlFormat returns the SenML content type named in the Accept header, or
+// an empty string when SenML wasn't asked for.
+func senmlFormat(r *http.Request) string {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accept))
+		if mediaType == senml.ContentTypeJSON || mediaType == senml.ContentTypeCBOR {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+// writeSenML writes a page of measurements as a pack, the cursor for the next
+// page is sent in a Link header.
+func writeSenML(w http.ResponseWriter, r *http.Request, contentType string, page *models.MeasurementPage) error {
+	pack := make(senml.Pack, 0, len(page.Measurements))
+	for _, measurement := range page.Measurements {
+		value := measurement.Value
+		pack = append(pack, senml.Record{
+			Name:  measurement.Type,
+			Unit:  measurement.Unit,
+			Value: &value,
+			Time:  senml.Time(measurement.ObservedAt),
+		})
+	}
+
+	setNextLink(w, r, page.Next)
+
+	w.Header().Set("Content-Type", contentType)
+	if contentType == senml.ContentTypeCBOR {
+		return senml.EncodeCBOR(w, pack)
+	}
+	return senml.EncodeJSON(w, pack)
+}
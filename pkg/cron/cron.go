@@ -0,0 +1,194 @@
+// Package cron parses five field cron expressions and matches them against
+// times.
+//
+// The fields are minute, hour, day of month, month and day of week. Each
+// field is a comma separated list of *, a value, or a range a-b, optionally
+// stepped with /n. Days of the week run from 0 to 7, both 0 and 7 are Sunday.
+// Like cron, when both day fields are restricted a time matches if either of
+// them does.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrFieldCount = errors.New("cron: expression must have five fields")
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Schedule is a parsed expression, each field is a bitset of the values it
+// matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// Parse reads an expression such as "30 2 * * 0" for 02:30 every Sunday.
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, ErrFieldCount
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	s := &Schedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}
+	// Sunday may be written as 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(expr, ",") {
+		lo, hi, step, err := parseItem(item, f)
+		if err != nil {
+			return 0, err
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Reads *, n, a-b, each optionally followed by /step.
+func parseItem(item string, f field) (lo, hi, step int, err error) {
+	rangeExpr, stepExpr := item, ""
+	stepped := false
+	if i := strings.Index(item, "/"); i >= 0 {
+		rangeExpr, stepExpr, stepped = item[:i], item[i+1:], true
+	}
+
+	step = 1
+	if stepped {
+		step, err = strconv.Atoi(stepExpr)
+		if err != nil || step < 1 {
+			return 0, 0, 0, fmt.Errorf("cron: invalid step %q in %s", stepExpr, f.name)
+		}
+	}
+
+	switch {
+	case rangeExpr == "*":
+		lo, hi = f.min, f.max
+	case strings.Contains(rangeExpr, "-"):
+		bounds := strings.SplitN(rangeExpr, "-", 2)
+		if lo, err = value(bounds[0], f); err != nil {
+			return 0, 0, 0, err
+		}
+		if hi, err = value(bounds[1], f); err != nil {
+			return 0, 0, 0, err
+		}
+		if lo > hi {
+			return 0, 0, 0, fmt.Errorf("cron: invalid range %q in %s", rangeExpr, f.name)
+		}
+	default:
+		if lo, err = value(rangeExpr, f); err != nil {
+			return 0, 0, 0, err
+		}
+		hi = lo
+		// A stepped value runs to the end of the field, as in 5/15.
+		if stepped {
+			hi = f.max
+		}
+	}
+	return lo, hi, step, nil
+}
+
+func value(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: %s must be from %d to %d, got %q", f.name, f.min, f.max, s)
+	}
+	return v, nil
+}
+
+// Matches reports whether the minute containing t is in the schedule, in t's
+// location.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.minute&(1<<uint(t.Minute())) != 0 &&
+		s.hour&(1<<uint(t.Hour())) != 0 &&
+		s.dayMatches(t)
+}
+
+// Prev returns the latest minute at or before t that is in the schedule,
+// looking no further back than limit. It reports false if there is none.
+//
+// Days and hours that can't match are skipped whole, so a week is searched in
+// a few hundred steps rather than minute by minute.
+func (s *Schedule) Prev(t time.Time, limit time.Duration) (time.Time, bool) {
+	earliest := t.Add(-limit)
+	for m := t.Truncate(time.Minute); !m.Before(earliest); {
+		if s.Matches(m) {
+			return m, true
+		}
+		m = s.back(m)
+	}
+	return time.Time{}, false
+}
+
+// back steps from m to the last minute before it that could match: the end
+// of the previous day or hour when m's day or hour can't, otherwise the
+// previous minute.
+func (s *Schedule) back(m time.Time) time.Time {
+	year, month, day := m.Date()
+	var start time.Time
+	switch {
+	case !s.dayMatches(m):
+		start = time.Date(year, month, day, 0, 0, 0, 0, m.Location())
+	case s.hour&(1<<uint(m.Hour())) == 0:
+		start = time.Date(year, month, day, m.Hour(), 0, 0, 0, m.Location())
+	default:
+		return m.Add(-time.Minute)
+	}
+	// Around daylight saving changes the start of an hour may be ambiguous,
+	// never step forwards.
+	if !start.Before(m) {
+		return m.Add(-time.Minute)
+	}
+	return start.Add(-time.Minute)
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
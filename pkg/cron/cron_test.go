@@ -0,0 +1,171 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		expr string
+		ok   bool
+	}{
+		{"* * * * *", true},
+		{"30 2 * * 0", true},
+		{"0,15,30,45 * * * *", true},
+		{"*/15 9-17 * * 1-5", true},
+		{"5/15 * * * *", true},
+		{"0 0 1 1 7", true},
+		{"59 23 31 12 7", true},
+		{"  0   0 * *   *  ", true},
+
+		{"", false},
+		{"* * * *", false},
+		{"* * * * * *", false},
+		{"60 * * * *", false},
+		{"-1 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * 32 * *", false},
+		{"* * * 0 *", false},
+		{"* * * 13 *", false},
+		{"* * * * 8", false},
+		{"5-1 * * * *", false},
+		{"1- * * * *", false},
+		{"-5 * * * *", false},
+		{"*/0 * * * *", false},
+		{"*/-1 * * * *", false},
+		{"*/ * * * *", false},
+		{"/5 * * * *", false},
+		{"1,,2 * * * *", false},
+		{", * * * *", false},
+		{"a * * * *", false},
+		{"MON * * * *", false},
+		{"1.5 * * * *", false},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.expr)
+		if (err == nil) != c.ok {
+			t.Errorf("Parse(%q) error = %v, want ok %v", c.expr, err, c.ok)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// 2026-10-18 is a Sunday.
+	sunday := time.Date(2026, 10, 18, 2, 30, 45, 0, time.UTC)
+	cases := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"* * * * *", sunday, true},
+		{"30 2 * * 0", sunday, true},
+		{"30 2 * * 7", sunday, true},
+		{"30 2 * * 1", sunday, false},
+		{"31 2 * * *", sunday, false},
+		{"30 3 * * *", sunday, false},
+		{"*/15 * * * *", sunday, true},
+		{"*/20 * * * *", sunday, false},
+		{"5/25 * * * *", sunday, true},
+		{"0-29 * * * *", sunday, false},
+		{"* * 18 10 *", sunday, true},
+		{"* * 18 11 *", sunday, false},
+		// When both day fields are restricted either may match.
+		{"* * 1 * 0", sunday, true},
+		{"* * 18 * 1", sunday, true},
+		{"* * 1 * 1", sunday, false},
+		// Otherwise both must.
+		{"* * 18 * *", sunday, true},
+		{"* * * * 0", sunday, true},
+		{"* * 1 * *", sunday, false},
+		// Fields are read in the time's own location.
+		{"30 4 * * *", sunday.In(time.FixedZone("CEST", 2*60*60)), true},
+		{"30 2 * * *", sunday.In(time.FixedZone("CEST", 2*60*60)), false},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.expr, err)
+		}
+		if got := s.Matches(c.at); got != c.want {
+			t.Errorf("%q matches %v = %v, want %v", c.expr, c.at, got, c.want)
+		}
+	}
+}
+
+func TestPrev(t *testing.T) {
+	at := time.Date(2026, 10, 18, 2, 30, 45, 0, time.UTC)
+	cases := []struct {
+		expr  string
+		limit time.Duration
+		want  time.Time
+		ok    bool
+	}{
+		{"* * * * *", 45 * time.Second, time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC), true},
+		{"* * * * *", 0, time.Time{}, false},
+		{"30 2 * * *", time.Hour, time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC), true},
+		{"0 2 * * *", time.Hour, time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), true},
+		// The limit is inclusive, measured from t rather than its minute.
+		{"0 2 * * *", 30*time.Minute + 45*time.Second, time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), true},
+		{"0 2 * * *", 30*time.Minute + 44*time.Second, time.Time{}, false},
+		{"31 2 * * *", 24 * time.Hour, time.Date(2026, 10, 17, 2, 31, 0, 0, time.UTC), true},
+		{"31 2 * * *", 23 * time.Hour, time.Time{}, false},
+		{"0 0 * * 1", 7 * 24 * time.Hour, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), true},
+		{"0 0 1 1 *", 7 * 24 * time.Hour, time.Time{}, false},
+		{"59 23 31 12 *", 366 * 24 * time.Hour, time.Date(2025, 12, 31, 23, 59, 0, 0, time.UTC), true},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.expr, err)
+		}
+		got, ok := s.Prev(at, c.limit)
+		if ok != c.ok || !got.Equal(c.want) {
+			t.Errorf("%q Prev(%v, %v) = %v, %v, want %v, %v", c.expr, at, c.limit, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+// Prev steps over whole days and hours, which must not skip matches across
+// daylight saving changes or in zones offset by part of an hour.
+func TestPrevAgreesWithMinuteByMinute(t *testing.T) {
+	zones := []string{"UTC", "Australia/Sydney", "Asia/Kolkata", "Australia/Lord_Howe", "America/New_York"}
+	exprs := []string{"30 2 * * *", "0 2 * * 0", "15 1,3 * * *", "*/20 0-3 * * *", "0 0 1 * *", "59 23 * * 6", "45 * 5 4 *"}
+	// Each range holds a daylight saving change for one of the zones.
+	starts := []time.Time{
+		time.Date(2026, 4, 5, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 4, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for _, zone := range zones {
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Skipf("no zone data: %v", err)
+		}
+		for _, expr := range exprs {
+			s, err := Parse(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, start := range starts {
+				at := start.In(location)
+				got, ok := s.Prev(at, 3*24*time.Hour)
+				want, wantOK := slowPrev(s, at, 3*24*time.Hour)
+				if ok != wantOK || !got.Equal(want) {
+					t.Errorf("%q in %s Prev(%v) = %v, %v, want %v, %v", expr, zone, at, got, ok, want, wantOK)
+				}
+			}
+		}
+	}
+}
+
+func slowPrev(s *Schedule, t time.Time, limit time.Duration) (time.Time, bool) {
+	for m := t.Truncate(time.Minute); !m.Before(t.Add(-limit)); m = m.Add(-time.Minute) {
+		if s.Matches(m) {
+			return m, true
+		}
+	}
+	return time.Time{}, false
+}
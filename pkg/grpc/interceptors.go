@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	context "context"
+
+	"github.com/jinzhu/gorm"
+	"github.com/naspinall/Hive/pkg/models"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	status "google.golang.org/grpc/status"
+)
+
+// authorizationKey is the metadata key holding the bearer token, the same
+// "Bearer <jwt>" value the HTTP API takes in its Authorization header.
+const authorizationKey = "authorization"
+
+// authenticate adds the caller's claims to ctx, so the service decorators
+// enforce RBAC just as they do for HTTP requests.
+func authenticate(us models.UserService, ctx context.Context) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationKey); len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	ctx, err := us.AcceptToken(&models.User{Token: token}, ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return ctx, nil
+}
+
+// UnaryAuthInterceptor authenticates unary calls with UserService.AcceptToken.
+func UnaryAuthInterceptor(us models.UserService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(us, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates streaming calls with
+// UserService.AcceptToken.
+func StreamAuthInterceptor(us models.UserService) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(us, ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryErrorInterceptor converts service errors to gRPC status errors.
+func UnaryErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, statusError(err)
+	}
+}
+
+// StreamErrorInterceptor converts service errors to gRPC status errors.
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return statusError(handler(srv, ss))
+	}
+}
+
+// statusError maps errors the way controllers.ProcessError does for HTTP. A
+// caller that lacks a role has been authenticated, so ErrorUnauthorized is
+// PermissionDenied rather than Unauthenticated.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch e := err.(type) {
+	case models.ErrorUnauthorized:
+		return status.Error(codes.PermissionDenied, e.Error())
+	case models.ErrorNotFound:
+		return status.Error(codes.NotFound, e.Error())
+	case models.ErrorBadRequest:
+		return status.Error(codes.InvalidArgument, e.Error())
+	case models.ErrorConflict:
+		return status.Error(codes.Aborted, e.Error())
+	}
+	if gorm.IsRecordNotFoundError(err) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
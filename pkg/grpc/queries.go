@@ -0,0 +1,331 @@
+package grpc
+
+import (
+	context "context"
+
+	"github.com/naspinall/Hive/pkg/models"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var errIDRequired = status.Error(codes.InvalidArgument, "ID Required")
+
+func fromMeasurement(m *models.Measurement) *Measurement {
+	return &Measurement{
+		ID:         int64(m.ID),
+		Type:       m.Type,
+		Value:      m.Value,
+		Unit:       m.Unit,
+		DeviceID:   int64(m.DeviceID),
+		ObservedAt: timestamppb.New(m.ObservedAt),
+		CreatedAt:  timestamppb.New(m.CreatedAt),
+		UpdatedAt:  timestamppb.New(m.UpdatedAt),
+	}
+}
+
+func (s *measurementServer) GetMeasurement(ctx context.Context, req *IDRequest) (*Measurement, error) {
+	measurement, err := s.ms.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fromMeasurement(measurement), nil
+}
+
+func (s *measurementServer) ListMeasurements(ctx context.Context, req *ListMeasurementsRequest) (*ListMeasurementsResponse, error) {
+	if req.DeviceID == 0 {
+		return nil, models.ErrDeviceIDRequired
+	}
+
+	query := &models.MeasurementQuery{
+		Type:   req.Type,
+		Order:  models.OrderAscending,
+		Limit:  int(req.PageSize),
+		Cursor: req.PageToken,
+	}
+	if req.Descending {
+		query.Order = models.OrderDescending
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		query.From = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		query.To = &to
+	}
+
+	page, err := s.ms.ByDevice(uint(req.DeviceID), query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListMeasurementsResponse{NextPageToken: page.Next}
+	for i := range page.Measurements {
+		resp.Measurements = append(resp.Measurements, fromMeasurement(&page.Measurements[i]))
+	}
+	return resp, nil
+}
+
+// UpdateMeasurement replaces the type, value and unit of a measurement. The
+// device and observation time are only changed when they are set.
+func (s *measurementServer) UpdateMeasurement(ctx context.Context, req *Measurement) (*Measurement, error) {
+	if req.ID == 0 {
+		return nil, errIDRequired
+	}
+	measurement, err := s.ms.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement.Type = req.Type
+	measurement.Value = req.Value
+	measurement.Unit = req.Unit
+	if req.DeviceID != 0 {
+		measurement.DeviceID = uint(req.DeviceID)
+	}
+	if req.ObservedAt != nil {
+		measurement.ObservedAt = req.ObservedAt.AsTime()
+	}
+
+	if err := s.ms.Update(measurement, ctx); err != nil {
+		return nil, err
+	}
+	return fromMeasurement(measurement), nil
+}
+
+func (s *measurementServer) DeleteMeasurement(ctx context.Context, req *IDRequest) (*Confirmation, error) {
+	if err := s.ms.Delete(uint(req.ID), ctx); err != nil {
+		return nil, err
+	}
+	return &Confirmation{Reply: 1}, nil
+}
+
+func fromDevice(d *models.Device) *Device {
+	return &Device{
+		ID:        int64(d.ID),
+		Name:      d.Name,
+		IMEI:      d.IMEI,
+		Longitude: d.Longitude,
+		Latitude:  d.Latitude,
+		CreatedAt: timestamppb.New(d.CreatedAt),
+		UpdatedAt: timestamppb.New(d.UpdatedAt),
+	}
+}
+
+func (s *deviceServer) GetDevice(ctx context.Context, req *IDRequest) (*Device, error) {
+	device, err := s.ds.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fromDevice(device), nil
+}
+
+func (s *deviceServer) ListDevices(ctx context.Context, req *ListDevicesRequest) (*ListDevicesResponse, error) {
+	query := &models.DeviceQuery{
+		PageQuery: models.PageQuery{Limit: int(req.PageSize), Cursor: req.PageToken},
+		Name:      req.Name,
+	}
+
+	page, err := s.ds.Query(query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListDevicesResponse{NextPageToken: page.Next}
+	for _, device := range page.Devices {
+		resp.Devices = append(resp.Devices, fromDevice(device))
+	}
+	return resp, nil
+}
+
+func (s *deviceServer) UpdateDevice(ctx context.Context, req *Device) (*Device, error) {
+	if req.ID == 0 {
+		return nil, errIDRequired
+	}
+	device, err := s.ds.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	device.Name = req.Name
+	device.IMEI = req.IMEI
+	device.Longitude = req.Longitude
+	device.Latitude = req.Latitude
+
+	if err := s.ds.Update(device, ctx); err != nil {
+		return nil, err
+	}
+	return fromDevice(device), nil
+}
+
+func (s *deviceServer) DeleteDevice(ctx context.Context, req *IDRequest) (*Confirmation, error) {
+	if err := s.ds.Delete(uint(req.ID), ctx); err != nil {
+		return nil, err
+	}
+	return &Confirmation{Reply: 1}, nil
+}
+
+func fromAlarm(a *models.Alarm) *Alarm {
+	return &Alarm{
+		ID:          int64(a.ID),
+		Type:        a.Type,
+		Status:      a.Status,
+		Severity:    a.Severity,
+		DeviceID:    int64(a.DeviceID),
+		CreatedAt:   timestamppb.New(a.CreatedAt),
+		UpdatedAt:   timestamppb.New(a.UpdatedAt),
+		Count:       int64(a.Count),
+		FirstSeenAt: timestamppb.New(a.FirstSeenAt),
+		LastSeenAt:  timestamppb.New(a.LastSeenAt),
+		Suppressed:  a.Suppressed,
+	}
+}
+
+func (s *alarmServer) GetAlarm(ctx context.Context, req *IDRequest) (*Alarm, error) {
+	alarm, err := s.as.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fromAlarm(alarm), nil
+}
+
+func (s *alarmServer) ListAlarms(ctx context.Context, req *ListAlarmsRequest) (*ListAlarmsResponse, error) {
+	query := &models.AlarmQuery{
+		PageQuery: models.PageQuery{Limit: int(req.PageSize), Cursor: req.PageToken},
+		DeviceID:  uint(req.DeviceID),
+		Type:      req.Type,
+		Status:    req.Status,
+		Severity:  req.Severity,
+	}
+
+	page, err := s.as.Query(query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListAlarmsResponse{NextPageToken: page.Next}
+	for _, alarm := range page.Alarms {
+		resp.Alarms = append(resp.Alarms, fromAlarm(alarm))
+	}
+	return resp, nil
+}
+
+// UpdateAlarm changes the severity or status of an alarm, whichever are set,
+// like the HTTP update. A status change is made as a transition, the type and
+// device are kept.
+func (s *alarmServer) UpdateAlarm(ctx context.Context, req *Alarm) (*Alarm, error) {
+	if req.ID == 0 {
+		return nil, errIDRequired
+	}
+	alarm, err := s.as.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Severity != "" && req.Severity != alarm.Severity {
+		alarm.Severity = req.Severity
+		if err := s.as.Update(alarm, ctx); err != nil {
+			return nil, err
+		}
+	}
+	if req.Status != "" && req.Status != alarm.Status {
+		if alarm, err = s.as.Transition(alarm.ID, req.Status, "", ctx); err != nil {
+			return nil, err
+		}
+	}
+	return fromAlarm(alarm), nil
+}
+
+func (s *alarmServer) DeleteAlarm(ctx context.Context, req *IDRequest) (*Confirmation, error) {
+	if err := s.as.Delete(uint(req.ID), ctx); err != nil {
+		return nil, err
+	}
+	return &Confirmation{Reply: 1}, nil
+}
+
+type subscriptionServer struct {
+	ss models.SubscriptionService
+}
+
+func fromSubscription(s *models.Subscription) *Subscription {
+	return &Subscription{
+		ID:        int64(s.ID),
+		Url:       s.Url,
+		Type:      s.Type,
+		Action:    s.Action,
+		DeviceID:  int64(s.DeviceID),
+		CreatedAt: timestamppb.New(s.CreatedAt),
+		UpdatedAt: timestamppb.New(s.UpdatedAt),
+	}
+}
+
+func (s *subscriptionServer) CreateSubscription(ctx context.Context, req *Subscription) (*Subscription, error) {
+	subscription := &models.Subscription{
+		Url:      req.Url,
+		Type:     req.Type,
+		Action:   req.Action,
+		DeviceID: uint(req.DeviceID),
+	}
+	if err := s.ss.Create(subscription, ctx); err != nil {
+		return nil, err
+	}
+	return fromSubscription(subscription), nil
+}
+
+func (s *subscriptionServer) GetSubscription(ctx context.Context, req *IDRequest) (*Subscription, error) {
+	subscription, err := s.ss.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fromSubscription(subscription), nil
+}
+
+func (s *subscriptionServer) ListSubscriptions(ctx context.Context, req *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error) {
+	query := &models.SubscriptionQuery{
+		PageQuery: models.PageQuery{Limit: int(req.PageSize), Cursor: req.PageToken},
+		DeviceID:  uint(req.DeviceID),
+		Type:      req.Type,
+		Action:    req.Action,
+	}
+
+	page, err := s.ss.Query(query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListSubscriptionsResponse{NextPageToken: page.Next}
+	for _, subscription := range page.Subscriptions {
+		resp.Subscriptions = append(resp.Subscriptions, fromSubscription(subscription))
+	}
+	return resp, nil
+}
+
+func (s *subscriptionServer) UpdateSubscription(ctx context.Context, req *Subscription) (*Subscription, error) {
+	if req.ID == 0 {
+		return nil, errIDRequired
+	}
+	subscription, err := s.ss.ByID(uint(req.ID), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Url = req.Url
+	subscription.Type = req.Type
+	subscription.Action = req.Action
+	if req.DeviceID != 0 {
+		subscription.DeviceID = uint(req.DeviceID)
+	}
+
+	if err := s.ss.Update(subscription, ctx); err != nil {
+		return nil, err
+	}
+	return fromSubscription(subscription), nil
+}
+
+func (s *subscriptionServer) DeleteSubscription(ctx context.Context, req *IDRequest) (*Confirmation, error) {
+	if err := s.ss.Delete(uint(req.ID), ctx); err != nil {
+		return nil, err
+	}
+	return &Confirmation{Reply: 1}, nil
+}
@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/naspinall/Hive/pkg/models"
+)
+
+// stubAlarms serves a single alarm and records the changes made to it.
+type stubAlarms struct {
+	models.AlarmService
+	alarm       models.Alarm
+	updates     []models.Alarm
+	transitions []string
+}
+
+func (s *stubAlarms) ByID(id uint, ctx context.Context) (*models.Alarm, error) {
+	alarm := s.alarm
+	return &alarm, nil
+}
+
+func (s *stubAlarms) Update(alarm *models.Alarm, ctx context.Context) error {
+	s.updates = append(s.updates, *alarm)
+	s.alarm = *alarm
+	return nil
+}
+
+func (s *stubAlarms) Transition(id uint, status, comment string, ctx context.Context) (*models.Alarm, error) {
+	s.transitions = append(s.transitions, status)
+	s.alarm.Status = status
+	alarm := s.alarm
+	return &alarm, nil
+}
+
+func TestUpdateAlarm(t *testing.T) {
+	cases := []struct {
+		name        string
+		req         *Alarm
+		severity    string
+		transitions []string
+	}{
+		{"severity", &Alarm{Severity: "MINOR"}, "MINOR", nil},
+		{"status", &Alarm{Status: models.AlarmAcknowledged}, "", []string{models.AlarmAcknowledged}},
+		{"both", &Alarm{Severity: "MINOR", Status: models.AlarmCleared}, "MINOR", []string{models.AlarmCleared}},
+		{"unchanged", &Alarm{Severity: "MAJOR", Status: models.AlarmActive}, "", nil},
+		// The type and device belong to the alarm's (device, type) key.
+		{"type and device", &Alarm{Type: "humidity", DeviceID: 9}, "", nil},
+	}
+	for _, c := range cases {
+		alarms := &stubAlarms{alarm: models.Alarm{
+			Model:    gorm.Model{ID: 4},
+			Type:     "temperature",
+			Status:   models.AlarmActive,
+			Severity: "MAJOR",
+			DeviceID: 1,
+		}}
+		c.req.ID = 4
+
+		got, err := (&alarmServer{as: alarms}).UpdateAlarm(context.Background(), c.req)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if c.severity == "" && len(alarms.updates) != 0 {
+			t.Errorf("%s: updated %+v", c.name, alarms.updates)
+		}
+		if c.severity != "" && (len(alarms.updates) != 1 || alarms.updates[0].Severity != c.severity) {
+			t.Errorf("%s: updates %+v, want severity %s", c.name, alarms.updates, c.severity)
+		}
+		if strings.Join(alarms.transitions, ",") != strings.Join(c.transitions, ",") {
+			t.Errorf("%s: transitions %v, want %v", c.name, alarms.transitions, c.transitions)
+		}
+		if got.Type != "temperature" || got.DeviceID != 1 {
+			t.Errorf("%s: returned type %q on device %d", c.name, got.Type, got.DeviceID)
+		}
+	}
+}
+
+func TestUpdateAlarmRequiresID(t *testing.T) {
+	if _, err := (&alarmServer{as: &stubAlarms{}}).UpdateAlarm(context.Background(), &Alarm{Severity: "MINOR"}); err != errIDRequired {
+		t.Errorf("error %v, want %v", err, errIDRequired)
+	}
+}
@@ -2,23 +2,53 @@ package grpc
 
 import (
 	context "context"
+	"io"
+	"log"
 
 	"github.com/naspinall/Hive/pkg/models"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-type modelsServer struct {
+// NewServer returns a gRPC server with the measurement, device, alarm and
+// subscription services registered, backed by services. Every call must carry a bearer
+// token in its authorization metadata.
+func NewServer(services *models.Services, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryErrorInterceptor(), UnaryAuthInterceptor(services.User)),
+		grpc.ChainStreamInterceptor(StreamErrorInterceptor(), StreamAuthInterceptor(services.User)),
+	}, opts...)
+	s := grpc.NewServer(opts...)
+	RegisterMeasurementServiceServer(s, &measurementServer{ms: services.Measurement, es: services.Events})
+	RegisterDeviceServiceServer(s, &deviceServer{ds: services.Device})
+	RegisterAlarmServiceServer(s, &alarmServer{as: services.Alarm, es: services.Events})
+	RegisterSubscriptionServiceServer(s, &subscriptionServer{ss: services.Subscription})
+	return s
+}
+
+type measurementServer struct {
 	ms models.MeasurementService
+	es models.EventService
 }
 
-func (s *modelsServer) CreateMeasurement(ctx context.Context, measurement *Measurement) (*Confirmation, error) {
+func toMeasurement(measurement *Measurement) *models.Measurement {
 	m := &models.Measurement{
 		Value:    measurement.Value,
-		DeviceID: int(measurement.DeviceID),
+		DeviceID: uint(measurement.DeviceID),
 		Type:     measurement.Type,
 		Unit:     measurement.Unit,
 	}
+	if measurement.ObservedAt != nil {
+		m.ObservedAt = measurement.ObservedAt.AsTime()
+	}
+	return m
+}
 
-	err := s.ms.Create(m, ctx)
+func (s *measurementServer) CreateMeasurement(ctx context.Context, measurement *Measurement) (*Confirmation, error) {
+	// Devices retrying a send reuse the message ID, so it doubles as the
+	// idempotency key.
+	ctx = models.WithIdempotencyKey(ctx, measurement.MessageID)
+	err := s.ms.Create(toMeasurement(measurement), ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -26,3 +56,183 @@ func (s *modelsServer) CreateMeasurement(ctx context.Context, measurement *Measu
 	return &Confirmation{Reply: 1}, nil
 
 }
+
+// CreateMeasurements inserts the streamed measurements in batches, the reply
+// counts the measurements that were accepted.
+func (s *measurementServer) CreateMeasurements(stream MeasurementService_CreateMeasurementsServer) error {
+	ctx := stream.Context()
+	var accepted int64
+	var batch []*models.Measurement
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := s.ms.CreateBatch(batch, ctx)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				log.Printf("Rejected streamed measurement: %s", result.Error)
+				continue
+			}
+			accepted++
+		}
+		batch = nil
+		return nil
+	}
+
+	for {
+		measurement, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, toMeasurement(measurement))
+		if len(batch) >= models.DefaultImportBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&Confirmation{Reply: accepted})
+}
+
+func (s *measurementServer) AggregateMeasurements(ctx context.Context, req *AggregateRequest) (*AggregateResponse, error) {
+	query := &models.AggregateQuery{
+		Type:     req.Type,
+		Bucket:   req.Bucket,
+		Function: req.Function,
+		Fill:     models.FillNone,
+	}
+	if req.FillEmpty {
+		query.Fill = models.FillNull
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		query.From = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		query.To = &to
+	}
+
+	buckets, err := s.ms.Aggregate(uint(req.DeviceID), query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AggregateResponse{}
+	for _, b := range buckets {
+		bucket := &AggregateBucket{Start: timestamppb.New(b.Start), Empty: b.Value == nil}
+		if b.Value != nil {
+			bucket.Value = *b.Value
+		}
+		resp.Buckets = append(resp.Buckets, bucket)
+	}
+	return resp, nil
+}
+
+type deviceServer struct {
+	ds models.DeviceService
+}
+
+func toDevice(device *Device) *models.Device {
+	return &models.Device{
+		Name:      device.Name,
+		IMEI:      device.IMEI,
+		Longitude: device.Longitude,
+		Latitude:  device.Latitude,
+	}
+}
+
+func (s *deviceServer) CreateDevice(ctx context.Context, device *Device) (*Confirmation, error) {
+	if err := s.ds.Create(toDevice(device), ctx); err != nil {
+		return nil, err
+	}
+	return &Confirmation{Reply: 1}, nil
+}
+
+// CreateDevices creates each streamed device, the reply counts the devices
+// that were created.
+func (s *deviceServer) CreateDevices(stream DeviceService_CreateDevicesServer) error {
+	ctx := stream.Context()
+	var accepted int64
+	for {
+		device, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		err = s.ds.Create(toDevice(device), ctx)
+		if _, ok := err.(models.ErrorUnauthorized); ok {
+			return err
+		}
+		if err != nil {
+			log.Printf("Rejected streamed device: %s", err)
+			continue
+		}
+		accepted++
+	}
+	return stream.SendAndClose(&Confirmation{Reply: accepted})
+}
+
+type alarmServer struct {
+	as models.AlarmService
+	es models.EventService
+}
+
+func toAlarm(alarm *Alarm) *models.Alarm {
+	return &models.Alarm{
+		Type:     alarm.Type,
+		Status:   alarm.Status,
+		Severity: alarm.Severity,
+		DeviceID: uint(alarm.DeviceID),
+	}
+}
+
+func (s *alarmServer) CreateAlarm(ctx context.Context, alarm *Alarm) (*Confirmation, error) {
+	ctx = models.WithIdempotencyKey(ctx, alarm.MessageID)
+	if err := s.as.Create(toAlarm(alarm), ctx); err != nil {
+		return nil, err
+	}
+	return &Confirmation{Reply: 1}, nil
+}
+
+// CreateAlarms creates each streamed alarm, the reply counts the alarms that
+// were created.
+func (s *alarmServer) CreateAlarms(stream AlarmService_CreateAlarmsServer) error {
+	ctx := stream.Context()
+	var accepted int64
+	for {
+		alarm, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		err = s.as.Create(toAlarm(alarm), models.WithIdempotencyKey(ctx, alarm.MessageID))
+		if _, ok := err.(models.ErrorUnauthorized); ok {
+			return err
+		}
+		if err != nil {
+			log.Printf("Rejected streamed alarm: %s", err)
+			continue
+		}
+		accepted++
+	}
+	return stream.SendAndClose(&Confirmation{Reply: accepted})
+}
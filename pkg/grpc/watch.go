@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"github.com/naspinall/Hive/pkg/models"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+func deviceIDs(ids []int64) []uint {
+	var deviceIDs []uint
+	for _, id := range ids {
+		deviceIDs = append(deviceIDs, uint(id))
+	}
+	return deviceIDs
+}
+
+// stream sends events from w until the client goes away or the watcher is
+// disconnected for falling behind.
+func stream(w *models.Watcher, done <-chan struct{}, send func(models.Event) error) error {
+	defer w.Close()
+	for {
+		select {
+		case event, ok := <-w.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, w.Err().Error())
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+func (s *measurementServer) WatchMeasurements(req *WatchMeasurementsRequest, srv MeasurementService_WatchMeasurementsServer) error {
+	filter := models.EventFilter{
+		DeviceIDs: deviceIDs(req.DeviceIDs),
+		Type:      req.Type,
+	}
+	w, err := s.es.WatchMeasurements(filter, srv.Context())
+	if err != nil {
+		return err
+	}
+
+	return stream(w, srv.Context().Done(), func(e models.Event) error {
+		return srv.Send(&MeasurementEvent{Action: e.Action, Measurement: fromMeasurement(e.Measurement)})
+	})
+}
+
+func (s *alarmServer) WatchAlarms(req *WatchAlarmsRequest, srv AlarmService_WatchAlarmsServer) error {
+	filter := models.EventFilter{
+		DeviceIDs: deviceIDs(req.DeviceIDs),
+		Type:      req.Type,
+		Severity:  req.Severity,
+	}
+	w, err := s.es.WatchAlarms(filter, srv.Context())
+	if err != nil {
+		return err
+	}
+
+	return stream(w, srv.Context().Done(), func(e models.Event) error {
+		return srv.Send(&AlarmEvent{Action: e.Action, Alarm: fromAlarm(e.Alarm)})
+	})
+}
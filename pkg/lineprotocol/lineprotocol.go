@@ -0,0 +1,186 @@
+// Package lineprotocol parses the InfluxDB line protocol.
+//
+//	weather,location=us-midwest temperature=82,humidity=71i 1465839830100400200
+package lineprotocol
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingFields      = errors.New("missing fields")
+	ErrMissingMeasurement = errors.New("missing measurement name")
+	ErrInvalidTag         = errors.New("invalid tag")
+	ErrInvalidField       = errors.New("invalid field")
+	ErrInvalidTimestamp   = errors.New("invalid timestamp")
+	ErrUnterminatedString = errors.New("unterminated string field")
+)
+
+// Precisions maps the precision query parameter used by Influx clients to
+// the unit of the timestamp.
+var Precisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"ns": time.Nanosecond,
+	"n":  time.Nanosecond,
+	"us": time.Microsecond,
+	"u":  time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// Field is a single field of a point, Value is a float64, int64, uint64,
+// bool or string.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Point is a parsed line, Time is zero when the line had no timestamp.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      []Field
+	Time        time.Time
+}
+
+// IsBlank reports whether a line holds no point, either empty or a comment.
+func IsBlank(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// Parse parses a single line, with timestamps counted in units of precision.
+func Parse(line string, precision time.Duration) (*Point, error) {
+	line = strings.TrimSpace(line)
+
+	keyEnd := indexUnescaped(line, ' ', false)
+	if keyEnd < 0 {
+		return nil, ErrMissingFields
+	}
+	key, rest := line[:keyEnd], strings.TrimLeft(line[keyEnd:], " ")
+
+	fieldsEnd := indexUnescaped(rest, ' ', true)
+	if fieldsEnd < 0 {
+		fieldsEnd = len(rest)
+	}
+	fields, timestamp := rest[:fieldsEnd], strings.TrimSpace(rest[fieldsEnd:])
+	if fields == "" {
+		return nil, ErrMissingFields
+	}
+
+	p := &Point{Tags: map[string]string{}}
+
+	parts := splitUnescaped(key, ',', false)
+	p.Measurement = unescape(parts[0])
+	if p.Measurement == "" {
+		return nil, ErrMissingMeasurement
+	}
+	for _, tag := range parts[1:] {
+		i := indexUnescaped(tag, '=', false)
+		if i <= 0 || i == len(tag)-1 {
+			return nil, ErrInvalidTag
+		}
+		p.Tags[unescape(tag[:i])] = unescape(tag[i+1:])
+	}
+
+	for _, field := range splitUnescaped(fields, ',', true) {
+		i := indexUnescaped(field, '=', false)
+		if i <= 0 || i == len(field)-1 {
+			return nil, ErrInvalidField
+		}
+		value, err := parseValue(field[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		p.Fields = append(p.Fields, Field{Key: unescape(field[:i]), Value: value})
+	}
+
+	if timestamp != "" {
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		// The time must still fit in nanoseconds once scaled.
+		if err != nil || ts > math.MaxInt64/int64(precision) || ts < math.MinInt64/int64(precision) {
+			return nil, ErrInvalidTimestamp
+		}
+		p.Time = time.Unix(0, ts*int64(precision)).UTC()
+	}
+	return p, nil
+}
+
+func parseValue(v string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(v, `"`):
+		if len(v) < 2 || !strings.HasSuffix(v, `"`) {
+			return nil, ErrUnterminatedString
+		}
+		s := v[1 : len(v)-1]
+		s = strings.Replace(s, `\"`, `"`, -1)
+		return strings.Replace(s, `\\`, `\`, -1), nil
+	case strings.HasSuffix(v, "i"):
+		i, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidField
+		}
+		return i, nil
+	case strings.HasSuffix(v, "u"):
+		u, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidField
+		}
+		return u, nil
+	}
+
+	switch v {
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+
+	// Line protocol has no NaN or infinity, though ParseFloat reads both.
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, ErrInvalidField
+	}
+	return f, nil
+}
+
+// Returns the index of the first sep that isn't escaped, or inside a string
+// when quoted is set.
+func indexUnescaped(s string, sep byte, quoted bool) int {
+	inString := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quoted && s[i] == '"':
+			inString = !inString
+		case s[i] == sep && !inString:
+			return i
+		}
+	}
+	return -1
+}
+
+func splitUnescaped(s string, sep byte, quoted bool) []string {
+	var parts []string
+	for {
+		i := indexUnescaped(s, sep, quoted)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+}
+
+var unescaper = strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=", `\\`, `\`)
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,151 @@
+package lineprotocol
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	at := time.Unix(0, 1465839830100400200).UTC()
+	cases := []struct {
+		line string
+		want *Point
+		err  error
+	}{
+		{
+			line: "weather,location=us-midwest temperature=82,humidity=71i 1465839830100400200",
+			want: &Point{
+				Measurement: "weather",
+				Tags:        map[string]string{"location": "us-midwest"},
+				Fields:      []Field{{"temperature", 82.0}, {"humidity", int64(71)}},
+				Time:        at,
+			},
+		},
+		{
+			line: "cpu load=0.5",
+			want: &Point{Measurement: "cpu", Tags: map[string]string{}, Fields: []Field{{"load", 0.5}}},
+		},
+		{
+			line: "  cpu   load=1e3   1465839830100400200  ",
+			want: &Point{Measurement: "cpu", Tags: map[string]string{}, Fields: []Field{{"load", 1000.0}}, Time: at},
+		},
+		{
+			line: `my\ cpu,host\,name=a\=b\ c load=-1.5,up=t,down=FALSE,count=18446744073709551615u`,
+			want: &Point{
+				Measurement: "my cpu",
+				Tags:        map[string]string{"host,name": `a=b c`},
+				Fields:      []Field{{"load", -1.5}, {"up", true}, {"down", false}, {"count", uint64(18446744073709551615)}},
+			},
+		},
+		{
+			line: `log msg="a \"quoted\", spaced = string",path="C:\\temp" 0`,
+			want: &Point{
+				Measurement: "log",
+				Tags:        map[string]string{},
+				Fields:      []Field{{"msg", `a "quoted", spaced = string`}, {"path", `C:\temp`}},
+				Time:        time.Unix(0, 0).UTC(),
+			},
+		},
+		{
+			line: "cpu load=9223372036854775807i,low=-9223372036854775808i -1",
+			want: &Point{
+				Measurement: "cpu",
+				Tags:        map[string]string{},
+				Fields:      []Field{{"load", int64(9223372036854775807)}, {"low", int64(-9223372036854775808)}},
+				Time:        time.Unix(0, -1).UTC(),
+			},
+		},
+
+		{line: "", err: ErrMissingFields},
+		{line: "cpu", err: ErrMissingFields},
+		{line: "cpu ", err: ErrMissingFields},
+		{line: "cpu,host=a", err: ErrMissingFields},
+		{line: " load=1", err: ErrMissingFields},
+		{line: ",host=a load=1", err: ErrMissingMeasurement},
+		{line: "cpu,host load=1", err: ErrInvalidTag},
+		{line: "cpu,=a load=1", err: ErrInvalidTag},
+		{line: "cpu,host= load=1", err: ErrInvalidTag},
+		{line: "cpu,host=a, load=1", err: ErrInvalidTag},
+		{line: "cpu load", err: ErrInvalidField},
+		{line: "cpu =1", err: ErrInvalidField},
+		{line: "cpu load=", err: ErrInvalidField},
+		{line: "cpu load=1,", err: ErrInvalidField},
+		{line: "cpu load=abc", err: ErrInvalidField},
+		{line: "cpu load=1.5i", err: ErrInvalidField},
+		{line: "cpu load=-1u", err: ErrInvalidField},
+		{line: "cpu load=9223372036854775808i", err: ErrInvalidField},
+		{line: "cpu load=18446744073709551616u", err: ErrInvalidField},
+		{line: "cpu load=1e400", err: ErrInvalidField},
+		{line: "cpu load=NaN", err: ErrInvalidField},
+		{line: "cpu load=nan", err: ErrInvalidField},
+		{line: "cpu load=Inf", err: ErrInvalidField},
+		{line: "cpu load=-inf", err: ErrInvalidField},
+		{line: "cpu load=+Infinity", err: ErrInvalidField},
+		{line: `cpu msg="unterminated`, err: ErrUnterminatedString},
+		{line: `cpu msg="`, err: ErrUnterminatedString},
+		{line: "cpu load=1 12.5", err: ErrInvalidTimestamp},
+		{line: "cpu load=1 now", err: ErrInvalidTimestamp},
+		{line: "cpu load=1 9223372036854775808", err: ErrInvalidTimestamp},
+		{line: "cpu load=1 1 2", err: ErrInvalidTimestamp},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.line, time.Nanosecond)
+		if err != c.err {
+			t.Errorf("Parse(%q) error = %v, want %v", c.line, err, c.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	cases := []struct {
+		precision string
+		timestamp string
+		want      time.Time
+		err       error
+	}{
+		{"", "1465839830100400200", time.Unix(0, 1465839830100400200).UTC(), nil},
+		{"us", "1465839830100400", time.Unix(0, 1465839830100400000).UTC(), nil},
+		{"ms", "1465839830100", time.Unix(0, 1465839830100000000).UTC(), nil},
+		{"s", "1465839830", time.Unix(1465839830, 0).UTC(), nil},
+		{"m", "24430663", time.Unix(24430663*60, 0).UTC(), nil},
+		{"h", "407177", time.Unix(407177*3600, 0).UTC(), nil},
+		{"s", "-1", time.Unix(-1, 0).UTC(), nil},
+		// Timestamps that don't fit in nanoseconds.
+		{"s", "9223372037", time.Time{}, ErrInvalidTimestamp},
+		{"s", "-9223372037", time.Time{}, ErrInvalidTimestamp},
+		{"h", "2562048", time.Time{}, ErrInvalidTimestamp},
+		{"ms", "9223372036855", time.Time{}, ErrInvalidTimestamp},
+	}
+	for _, c := range cases {
+		p, err := Parse("cpu load=1 "+c.timestamp, Precisions[c.precision])
+		if err != c.err {
+			t.Errorf("%s %s: error = %v, want %v", c.precision, c.timestamp, err, c.err)
+			continue
+		}
+		if err == nil && !p.Time.Equal(c.want) {
+			t.Errorf("%s %s: time = %v, want %v", c.precision, c.timestamp, p.Time, c.want)
+		}
+	}
+}
+
+func TestIsBlank(t *testing.T) {
+	cases := map[string]bool{
+		"":                 true,
+		"   ":              true,
+		"\t":               true,
+		"# comment":        true,
+		"  # indented":     true,
+		"cpu load=1":       false,
+		"cpu load=1 # not": false,
+	}
+	for line, want := range cases {
+		if got := IsBlank(line); got != want {
+			t.Errorf("IsBlank(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
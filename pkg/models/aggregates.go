@@ -0,0 +1,252 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Aggregation functions supported over a bucket of measurements.
+const (
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateAvg   = "avg"
+	AggregateSum   = "sum"
+	AggregateCount = "count"
+	AggregateFirst = "first"
+	AggregateLast  = "last"
+)
+
+// How buckets without any measurements are returned.
+const (
+	FillNone = "none"
+	FillNull = "null"
+)
+
+// AggregateBuckets are the bucket widths that can be requested.
+var AggregateBuckets = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+var aggregateExpressions = map[string]string{
+	AggregateMin:   "min(value)",
+	AggregateMax:   "max(value)",
+	AggregateAvg:   "avg(value)",
+	AggregateSum:   "sum(value)",
+	AggregateCount: "count(*)::double precision",
+	AggregateFirst: "(array_agg(value ORDER BY observed_at ASC, id ASC))[1]",
+	AggregateLast:  "(array_agg(value ORDER BY observed_at DESC, id DESC))[1]",
+}
+
+// AggregateQuery describes an aggregation of a single measurement type over
+// fixed width time buckets.
+type AggregateQuery struct {
+	Type     string
+	Bucket   string
+	Function string
+	From     *time.Time
+	To       *time.Time
+	Fill     string
+}
+
+// AggregateBucket is the aggregated value of a single bucket, Value is nil
+// for empty buckets when filling with null.
+type AggregateBucket struct {
+	Start time.Time `json:"start"`
+	Value *float64  `json:"value"`
+}
+
+// ParseBucket converts a bucket name such as 5m into its width.
+func ParseBucket(bucket string) (time.Duration, error) {
+	width, ok := AggregateBuckets[bucket]
+	if !ok {
+		return 0, ErrInvalidBucket
+	}
+	return width, nil
+}
+
+func (q *AggregateQuery) validate() error {
+	if q.Type == "" {
+		return ErrTypeRequired
+	}
+	if _, err := ParseBucket(q.Bucket); err != nil {
+		return err
+	}
+	if _, ok := aggregateExpressions[q.Function]; !ok {
+		return ErrInvalidAggregate
+	}
+
+	switch q.Fill {
+	case "":
+		q.Fill = FillNone
+	case FillNone, FillNull:
+	default:
+		return ErrInvalidFill
+	}
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// Equivalent expressions over the summaries stored in measurement_rollups.
+var rollupExpressions = map[string]string{
+	AggregateMin:   "min(minimum)",
+	AggregateMax:   "max(maximum)",
+	AggregateAvg:   "sum(total) / sum(count)",
+	AggregateSum:   "sum(total)",
+	AggregateCount: "sum(count)::double precision",
+	AggregateFirst: "(array_agg(first_value ORDER BY first_at ASC))[1]",
+	AggregateLast:  "(array_agg(last_value ORDER BY last_at DESC))[1]",
+}
+
+func (mg *measurementGorm) Aggregate(id uint, query *AggregateQuery, ctx context.Context) ([]AggregateBucket, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	width, _ := ParseBucket(query.Bucket)
+
+	// Reading from the coarsest rollup that fits the bucket, up to the last
+	// bucket the rollup worker has computed. Anything newer comes from the
+	// raw measurements.
+	buckets := []AggregateBucket{}
+	rawFrom := query.From
+	if r := rollupFor(width); r != nil {
+		var latest *time.Time
+		row := mg.db.Model(&MeasurementRollup{}).
+			Where("device_id = ? AND type = ? AND resolution = ?", id, query.Type, r.Name).
+			Select("max(bucket_start)").Row()
+		if err := row.Scan(&latest); err != nil {
+			return nil, err
+		}
+
+		if latest != nil {
+			split := latest.Add(r.Width).UTC().Truncate(width)
+			if query.To != nil && query.To.Before(split) {
+				split = *query.To
+			}
+
+			db := mg.db.Model(&MeasurementRollup{}).
+				Where("device_id = ? AND type = ? AND resolution = ?", id, query.Type, r.Name).
+				Where("bucket_start < ?", split)
+			if query.From != nil {
+				db = db.Where("bucket_start >= ?", *query.From)
+			}
+			rolled, err := scanBuckets(db, "bucket_start", rollupExpressions[query.Function], width)
+			if err != nil {
+				return nil, err
+			}
+			buckets = append(buckets, rolled...)
+
+			if rawFrom == nil || rawFrom.Before(split) {
+				rawFrom = &split
+			}
+		}
+	}
+
+	if query.To == nil || rawFrom == nil || rawFrom.Before(*query.To) {
+		db := mg.db.Model(&Measurement{}).
+			Where("device_id = ?", id).
+			Where("type = ?", query.Type)
+		if rawFrom != nil {
+			db = db.Where("observed_at >= ?", *rawFrom)
+		}
+		if query.To != nil {
+			db = db.Where("observed_at < ?", *query.To)
+		}
+		raw, err := scanBuckets(db, "observed_at", aggregateExpressions[query.Function], width)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, raw...)
+	}
+
+	if query.Fill == FillNull {
+		buckets = fillBuckets(buckets, width, query.From, query.To)
+	}
+	return buckets, nil
+}
+
+// Groups the rows selected by db into buckets of width on column.
+func scanBuckets(db *gorm.DB, column, expression string, width time.Duration) ([]AggregateBucket, error) {
+	seconds := int64(width / time.Second)
+	selection := fmt.Sprintf(
+		"to_timestamp(floor(extract(epoch from %[1]s) / %[2]d) * %[2]d) AS bucket, %[3]s AS value",
+		column, seconds, expression,
+	)
+
+	rows, err := db.Select(selection).Group("bucket").Order("bucket").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []AggregateBucket{}
+	for rows.Next() {
+		var bucket AggregateBucket
+		if err := rows.Scan(&bucket.Start, &bucket.Value); err != nil {
+			return nil, err
+		}
+		bucket.Start = bucket.Start.UTC()
+		buckets = append(buckets, bucket)
+	}
+	return buckets, rows.Err()
+}
+
+// Inserts a null bucket for every gap between from and to, falling back to
+// the first and last populated bucket when the range is open.
+func fillBuckets(buckets []AggregateBucket, width time.Duration, from, to *time.Time) []AggregateBucket {
+	var start, end time.Time
+	switch {
+	case from != nil:
+		start = from.UTC().Truncate(width)
+	case len(buckets) > 0:
+		start = buckets[0].Start
+	default:
+		return buckets
+	}
+	switch {
+	case to != nil:
+		end = to.UTC()
+	case len(buckets) > 0:
+		end = buckets[len(buckets)-1].Start.Add(width)
+	default:
+		return buckets
+	}
+
+	filled := []AggregateBucket{}
+	i := 0
+	for at := start; at.Before(end); at = at.Add(width) {
+		if i < len(buckets) && buckets[i].Start.Equal(at) {
+			filled = append(filled, buckets[i])
+			i++
+			continue
+		}
+		filled = append(filled, AggregateBucket{Start: at})
+	}
+	return filled
+}
+
+func (ma *measurementAuditLogger) Aggregate(id uint, query *AggregateQuery, ctx context.Context) ([]AggregateBucket, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "Measurements")
+	return ma.MeasurementDB.Aggregate(id, query, ctx)
+}
+
+func (ma *measurementAuthorization) Aggregate(id uint, query *AggregateQuery, ctx context.Context) ([]AggregateBucket, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 1 {
+		return nil, ErrMeasurementReadRequired
+	}
+	return ma.MeasurementDB.Aggregate(id, query, ctx)
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Alarm sort keys, each is ordered on with the ID to break ties.
+const (
+	AlarmSortID       = "id"
+	AlarmSortCreated  = "created"
+	AlarmSortUpdated  = "updated"
+	AlarmSortLastSeen = "lastSeen"
+	AlarmSortSeverity = "severity"
+	AlarmSortCount    = "count"
+)
+
+// AlarmQuery filters a page of alarms, empty fields match every alarm. The
+// From times are inclusive and the To times exclusive.
+type AlarmQuery struct {
+	PageQuery
+	DeviceID    uint
+	Group       string
+	Type        string
+	Status      string
+	Severity    string
+	Suppressed  *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Sort        string
+	Order       string
+}
+
+type AlarmPage struct {
+	Alarms []*Alarm `json:"alarms"`
+	Next   string   `json:"next,omitempty"`
+}
+
+// AlarmSummary counts the alarms matching a query by severity and by status.
+// Every known severity and status is present, even when there are none.
+type AlarmSummary struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"bySeverity"`
+	ByStatus   map[string]int `json:"byStatus"`
+}
+
+// alarmSort is the column a sort key orders on, and how an alarm's value of
+// it is kept in a cursor.
+type alarmSort struct {
+	column string
+	key    func(*Alarm) int64
+	arg    func(int64) interface{}
+}
+
+// Severities sort by how severe they are rather than by name.
+const severityRank = `(CASE severity WHEN 'MINOR' THEN 1 WHEN 'MAJOR' THEN 2 WHEN 'SEVERE' THEN 3 ELSE 0 END)`
+
+var severityRanks = map[string]int64{
+	SeverityMinor:  1,
+	SeverityMajor:  2,
+	SeveritySevere: 3,
+}
+
+func timeArg(key int64) interface{} {
+	return time.Unix(0, key).UTC()
+}
+
+func intArg(key int64) interface{} {
+	return key
+}
+
+var alarmSorts = map[string]alarmSort{
+	AlarmSortID:       {"id", func(a *Alarm) int64 { return int64(a.ID) }, intArg},
+	AlarmSortCreated:  {"created_at", func(a *Alarm) int64 { return a.CreatedAt.UnixNano() }, timeArg},
+	AlarmSortUpdated:  {"updated_at", func(a *Alarm) int64 { return a.UpdatedAt.UnixNano() }, timeArg},
+	AlarmSortLastSeen: {"last_seen_at", func(a *Alarm) int64 { return a.LastSeenAt.UnixNano() }, timeArg},
+	AlarmSortSeverity: {severityRank, func(a *Alarm) int64 { return severityRanks[a.Severity] }, intArg},
+	AlarmSortCount:    {"count", func(a *Alarm) int64 { return int64(a.Count) }, intArg},
+}
+
+func (q *AlarmQuery) normalise() error {
+	switch q.Order {
+	case "":
+		q.Order = OrderAscending
+	case OrderAscending, OrderDescending:
+	default:
+		return ErrInvalidOrder
+	}
+
+	if q.Sort == "" {
+		q.Sort = AlarmSortID
+	}
+	if _, ok := alarmSorts[q.Sort]; !ok {
+		return ErrInvalidSort
+	}
+
+	if q.Status != "" {
+		if _, ok := alarmTransitions[q.Status]; !ok {
+			return ErrInvalidAlarmStatus
+		}
+	}
+	if q.Severity != "" && !validSeverity(q.Severity) {
+		return ErrInvalidSeverity
+	}
+
+	if q.CreatedFrom != nil && q.CreatedTo != nil && !q.CreatedFrom.Before(*q.CreatedTo) {
+		return ErrInvalidTimeRange
+	}
+	if q.UpdatedFrom != nil && q.UpdatedTo != nil && !q.UpdatedFrom.Before(*q.UpdatedTo) {
+		return ErrInvalidTimeRange
+	}
+
+	q.clamp()
+	return nil
+}
+
+// filter limits db to the alarms matching the query.
+func (q *AlarmQuery) filter(db *gorm.DB) *gorm.DB {
+	if q.DeviceID != 0 {
+		db = db.Where("device_id = ?", q.DeviceID)
+	}
+	if q.Group != "" {
+		db = db.Where(`device_id IN (SELECT id FROM devices WHERE "group" = ? AND deleted_at IS NULL)`, q.Group)
+	}
+	if q.Type != "" {
+		db = db.Where("type = ?", q.Type)
+	}
+	if q.Status != "" {
+		db = db.Where("status = ?", q.Status)
+	}
+	if q.Severity != "" {
+		db = db.Where("severity = ?", q.Severity)
+	}
+	if q.Suppressed != nil {
+		db = db.Where("suppressed = ?", *q.Suppressed)
+	}
+	if q.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *q.CreatedFrom)
+	}
+	if q.CreatedTo != nil {
+		db = db.Where("created_at < ?", *q.CreatedTo)
+	}
+	if q.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *q.UpdatedFrom)
+	}
+	if q.UpdatedTo != nil {
+		db = db.Where("updated_at < ?", *q.UpdatedTo)
+	}
+	return db
+}
+
+// The cursor holds the sort key and ID of the last alarm on the page, the
+// next page starts after them.
+func (ag *alarmGorm) Query(query *AlarmQuery, ctx context.Context) (*AlarmPage, error) {
+	if err := query.normalise(); err != nil {
+		return nil, err
+	}
+	sort := alarmSorts[query.Sort]
+
+	op, dir := ">", "ASC"
+	if query.Order == OrderDescending {
+		op, dir = "<", "DESC"
+	}
+
+	db := query.filter(ag.db)
+	if query.Cursor != "" {
+		key, id, err := decodeAlarmCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sort.column, op), sort.arg(key), id)
+	}
+	db = db.Order(fmt.Sprintf("%s %s, id %s", sort.column, dir, dir)).Limit(query.Limit + 1)
+
+	alarms := []*Alarm{}
+	if err := db.Find(&alarms).Error; err != nil {
+		return nil, err
+	}
+
+	page := &AlarmPage{Alarms: alarms}
+	if n, more := query.more(len(alarms)); more {
+		last := alarms[n-1]
+		page.Alarms = alarms[:n]
+		page.Next = encodeAlarmCursor(sort.key(last), last.ID)
+	}
+	return page, nil
+}
+
+// Summary ignores the query's sort and page.
+func (ag *alarmGorm) Summary(query *AlarmQuery, ctx context.Context) (*AlarmSummary, error) {
+	if err := query.normalise(); err != nil {
+		return nil, err
+	}
+
+	rows, err := query.filter(ag.db.Model(&Alarm{})).
+		Select("severity, status, count(*)").
+		Group("severity, status").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &AlarmSummary{
+		BySeverity: map[string]int{SeverityMinor: 0, SeverityMajor: 0, SeveritySevere: 0},
+		ByStatus:   map[string]int{AlarmActive: 0, AlarmAcknowledged: 0, AlarmCleared: 0},
+	}
+	for rows.Next() {
+		var severity, status string
+		var count int
+		if err := rows.Scan(&severity, &status, &count); err != nil {
+			return nil, err
+		}
+		summary.Total += count
+		summary.BySeverity[severity] += count
+		summary.ByStatus[status] += count
+	}
+	return summary, rows.Err()
+}
+
+func encodeAlarmCursor(key int64, id uint) string {
+	raw := fmt.Sprintf("%d:%d", key, id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAlarmCursor(cursor string) (int64, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, ErrInvalidCursor
+	}
+
+	var key int64
+	var id uint
+	// Sscanf stops at the second number, so anything after it is caught by
+	// encoding the cursor again.
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &key, &id); err != nil || encodeAlarmCursor(key, id) != cursor {
+		return 0, 0, ErrInvalidCursor
+	}
+	return key, id, nil
+}
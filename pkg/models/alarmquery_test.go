@@ -0,0 +1,206 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+func TestAlarmQueryNormalise(t *testing.T) {
+	early, late := rollupNow, rollupNow.Add(time.Hour)
+
+	cases := []struct {
+		name  string
+		query AlarmQuery
+		err   error
+	}{
+		{"defaults", AlarmQuery{}, nil},
+		{"filters", AlarmQuery{Status: AlarmAcknowledged, Severity: SeveritySevere, Sort: AlarmSortSeverity, Order: OrderDescending}, nil},
+		{"time ranges", AlarmQuery{CreatedFrom: &early, CreatedTo: &late, UpdatedFrom: &early, UpdatedTo: &late}, nil},
+		{"open time ranges", AlarmQuery{CreatedFrom: &late, UpdatedTo: &early}, nil},
+		{"bad order", AlarmQuery{Order: "up"}, ErrInvalidOrder},
+		{"bad sort", AlarmQuery{Sort: "type"}, ErrInvalidSort},
+		{"sort by column", AlarmQuery{Sort: "last_seen_at"}, ErrInvalidSort},
+		{"bad status", AlarmQuery{Status: "active"}, ErrInvalidAlarmStatus},
+		{"bad severity", AlarmQuery{Severity: "CRITICAL"}, ErrInvalidSeverity},
+		{"reversed created", AlarmQuery{CreatedFrom: &late, CreatedTo: &early}, ErrInvalidTimeRange},
+		{"empty created", AlarmQuery{CreatedFrom: &early, CreatedTo: &early}, ErrInvalidTimeRange},
+		{"reversed updated", AlarmQuery{UpdatedFrom: &late, UpdatedTo: &early}, ErrInvalidTimeRange},
+	}
+	for _, c := range cases {
+		q := c.query
+		if err := q.normalise(); err != c.err {
+			t.Errorf("%s: error %v, want %v", c.name, err, c.err)
+		}
+	}
+
+	q := AlarmQuery{PageQuery: PageQuery{Limit: MaxPageLimit + 1}}
+	if err := q.normalise(); err != nil {
+		t.Fatal(err)
+	}
+	if q.Order != OrderAscending || q.Sort != AlarmSortID || q.Limit != MaxPageLimit {
+		t.Errorf("normalised to order %q sort %q limit %d", q.Order, q.Sort, q.Limit)
+	}
+}
+
+func TestAlarmCursor(t *testing.T) {
+	for _, key := range []int64{0, 3, -1, rollupNow.UnixNano(), math.MaxInt64, math.MinInt64} {
+		for _, id := range []uint{0, 1, math.MaxUint32} {
+			gotKey, gotID, err := decodeAlarmCursor(encodeAlarmCursor(key, id))
+			if err != nil || gotKey != key || gotID != id {
+				t.Errorf("%d:%d came back as %d:%d, %v", key, id, gotKey, gotID, err)
+			}
+		}
+	}
+
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, cursor := range []string{
+		"",
+		"not base64!",
+		encode("12"),
+		encode("a:2"),
+		encode("1:b"),
+		encode("1:-2"),
+		encode("1:2:3"),
+		encode("1:2 "),
+		encode(" 1:2"),
+		encode("01:2"),
+	} {
+		if _, _, err := decodeAlarmCursor(cursor); err != ErrInvalidCursor {
+			t.Errorf("cursor %q: error %v, want %v", cursor, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestAlarmSortKeys(t *testing.T) {
+	seen := rollupNow.Add(time.Minute)
+	alarm := &Alarm{
+		Model:      gorm.Model{ID: 7, CreatedAt: rollupNow, UpdatedAt: seen},
+		Severity:   SeverityMajor,
+		Count:      4,
+		LastSeenAt: seen,
+	}
+	cases := []struct {
+		sort string
+		key  int64
+		arg  interface{}
+	}{
+		{AlarmSortID, 7, int64(7)},
+		{AlarmSortCreated, rollupNow.UnixNano(), rollupNow},
+		{AlarmSortUpdated, seen.UnixNano(), seen},
+		{AlarmSortLastSeen, seen.UnixNano(), seen},
+		{AlarmSortSeverity, 2, int64(2)},
+		{AlarmSortCount, 4, int64(4)},
+	}
+	for _, c := range cases {
+		sort := alarmSorts[c.sort]
+		key := sort.key(alarm)
+		if key != c.key || !reflect.DeepEqual(sort.arg(key), c.arg) {
+			t.Errorf("%s: key %d and argument %v, want %d and %v", c.sort, key, sort.arg(key), c.key, c.arg)
+		}
+	}
+
+	// Severities rank by how severe they are, unknown ones first.
+	ranks := []int64{}
+	for _, severity := range []string{"", SeverityMinor, SeverityMajor, SeveritySevere} {
+		ranks = append(ranks, alarmSorts[AlarmSortSeverity].key(&Alarm{Severity: severity}))
+	}
+	if !reflect.DeepEqual(ranks, []int64{0, 1, 2, 3}) {
+		t.Errorf("severity ranks %v", ranks)
+	}
+}
+
+func TestAlarmQueryBySeverity(t *testing.T) {
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, `FROM "alarms"`) {
+			return fakeResult{Columns: []string{"id", "severity"}, Rows: [][]driver.Value{
+				{int64(9), SeveritySevere}, {int64(4), SeverityMajor}, {int64(2), SeverityMajor},
+			}}
+		}
+		return fakeResult{}
+	})
+
+	query := &AlarmQuery{
+		PageQuery: PageQuery{Limit: 2, Cursor: encodeAlarmCursor(3, 12)},
+		Sort:      AlarmSortSeverity,
+		Order:     OrderDescending,
+	}
+	page, err := (&alarmGorm{db: db}).Query(query, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := fake.Calls(`FROM "alarms"`)
+	if len(calls) != 1 {
+		t.Fatalf("queries %q, want one alarm listing", fake.Queries())
+	}
+	for _, fragment := range []string{
+		"(" + severityRank + ", id) < ($",
+		"ORDER BY " + severityRank + " DESC, id DESC",
+		"LIMIT 3",
+	} {
+		if !strings.Contains(calls[0].Query, fragment) {
+			t.Errorf("query is missing %q: %s", fragment, calls[0].Query)
+		}
+	}
+	if !reflect.DeepEqual(calls[0].Args, []driver.Value{int64(3), int64(12)}) {
+		t.Errorf("started after %v, want severe alarm 12", calls[0].Args)
+	}
+
+	if len(page.Alarms) != 2 || page.Next != encodeAlarmCursor(2, 4) {
+		t.Errorf("page of %d alarms and next %q, want 2 and after major alarm 4", len(page.Alarms), page.Next)
+	}
+}
+
+func TestAlarmSummaryListsEverySeverityAndStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		rows     [][]driver.Value
+		total    int
+		severity map[string]int
+		status   map[string]int
+	}{
+		{
+			name:     "no alarms",
+			severity: map[string]int{SeverityMinor: 0, SeverityMajor: 0, SeveritySevere: 0},
+			status:   map[string]int{AlarmActive: 0, AlarmAcknowledged: 0, AlarmCleared: 0},
+		},
+		{
+			name: "some alarms",
+			rows: [][]driver.Value{
+				{SeverityMajor, AlarmActive, int64(2)},
+				{SeverityMajor, AlarmCleared, int64(3)},
+				{SeverityMinor, AlarmCleared, int64(1)},
+			},
+			total:    6,
+			severity: map[string]int{SeverityMinor: 1, SeverityMajor: 5, SeveritySevere: 0},
+			status:   map[string]int{AlarmActive: 2, AlarmAcknowledged: 0, AlarmCleared: 4},
+		},
+	}
+	for _, c := range cases {
+		db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+			if strings.Contains(query, `FROM "alarms"`) {
+				return fakeResult{Columns: []string{"severity", "status", "count"}, Rows: c.rows}
+			}
+			return fakeResult{}
+		})
+
+		summary, err := (&alarmGorm{db: db}).Summary(&AlarmQuery{DeviceID: 1}, context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.Total != c.total || !reflect.DeepEqual(summary.BySeverity, c.severity) || !reflect.DeepEqual(summary.ByStatus, c.status) {
+			t.Errorf("%s: summary %+v", c.name, summary)
+		}
+		if grouped := fake.Calls("GROUP BY severity, status", "device_id = $1"); len(grouped) != 1 {
+			t.Errorf("%s: queries %q, want one filtered grouping", c.name, fake.Queries())
+		}
+	}
+}
@@ -2,13 +2,17 @@ package models
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
 	"log"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
 
+// Alarm is raised against a device, and moves from ACTIVE through
+// ACKNOWLEDGED to CLEARED. Each step records who took it and when.
+//
+// A device has at most one open alarm of each type, raising it again counts
+// another occurrence rather than adding an alarm.
 type Alarm struct {
 	gorm.Model
 	Type     string `gorm:"not null"`
@@ -16,8 +20,49 @@ type Alarm struct {
 	Severity string `gorm:"not null"`
 	DeviceID uint
 	Device   Device `json:"-"`
+
+	// RuleID is the rule that raised the alarm, if any.
+	RuleID *uint
+	// Suppressed alarms were raised during maintenance, and skip webhooks,
+	// notifications and escalation.
+	Suppressed bool `gorm:"not null;default:false;index"`
+
+	Count       uint      `gorm:"not null;default:1"`
+	FirstSeenAt time.Time `gorm:"not null"`
+	LastSeenAt  time.Time `gorm:"not null"`
+
+	AcknowledgedBy     *uint
+	AcknowledgedAt     *time.Time
+	AcknowledgeComment string
+	ClearedBy          *uint
+	ClearedAt          *time.Time
+	ClearComment       string
+
+	// resurfaced is set by a create that repeated a suppressed alarm once
+	// its maintenance was over.
+	resurfaced bool
 }
 
+// alarmOpenIndex allows a single alarm that hasn't cleared per device and
+// type. AutoMigrate can't create partial indexes, so it is created by hand.
+const alarmOpenIndex = `
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alarms_open ON alarms (device_id, type)
+WHERE status <> 'CLEARED' AND deleted_at IS NULL`
+
+// Counts another occurrence of the open alarm with the same device and type,
+// keeping when it was first seen. Otherwise the alarm is inserted.
+// The open CTE reads the alarm as it was before the upsert, so an alarm that
+// was suppressed and no longer is can be raised.
+const alarmUpsert = `
+WITH open AS (
+	SELECT suppressed FROM alarms WHERE device_id = ? AND type = ? AND status <> 'CLEARED' AND deleted_at IS NULL
+)
+INSERT INTO alarms (created_at, updated_at, type, status, severity, device_id, rule_id, suppressed, count, first_seen_at, last_seen_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+ON CONFLICT (device_id, type) WHERE status <> 'CLEARED' AND deleted_at IS NULL DO UPDATE SET
+	count = alarms.count + 1, suppressed = EXCLUDED.suppressed, last_seen_at = EXCLUDED.last_seen_at, updated_at = EXCLUDED.updated_at
+RETURNING alarms.*, COALESCE((SELECT suppressed FROM open), false) AND NOT alarms.suppressed AS resurfaced`
+
 type alarmGorm struct {
 	db *gorm.DB
 }
@@ -33,6 +78,9 @@ type AlarmDB interface {
 	Update(alarm *Alarm, ctx context.Context) error
 	Delete(id uint, ctx context.Context) error
 	Many(count int, ctx context.Context) ([]*Alarm, error)
+	Query(query *AlarmQuery, ctx context.Context) (*AlarmPage, error)
+	Summary(query *AlarmQuery, ctx context.Context) (*AlarmSummary, error)
+	Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error)
 }
 
 type alarmWebhook struct {
@@ -44,12 +92,32 @@ type alarmAuthorization struct {
 	AlarmDB
 }
 
-func NewAlarmService(db *gorm.DB, Subscription SubscriptionService) AlarmService {
+func NewAlarmService(db *gorm.DB, Subscription SubscriptionService, hub *EventHub, notifier *Notifier, escalator *Escalator, idempotencyWindow time.Duration) AlarmService {
 	return &alarmAuthorization{
-		&alarmWebhook{
-			Subscription: Subscription,
-			AlarmDB: &alarmGorm{
+		&alarmIdempotency{
+			keys: newIdempotencyGorm(db, idempotencyWindow),
+			AlarmDB: &alarmMaintenance{
 				db: db,
+				AlarmDB: &alarmEvents{
+					hub: hub,
+					AlarmDB: &alarmEscalations{
+						escalator: escalator,
+						AlarmDB: &alarmNotifications{
+							notifier: notifier,
+							AlarmDB: &alarmWebhook{
+								Subscription: Subscription,
+								AlarmDB: &alarmValidator{
+									&alarmHistory{
+										db: db,
+										AlarmDB: &alarmGorm{
+											db: db,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -59,16 +127,15 @@ func (ag *alarmGorm) ByDevice(id uint, ctx context.Context) ([]Alarm, error) {
 
 	device := Device{Model: gorm.Model{ID: id}}
 	alarms := []Alarm{}
-	if err := ag.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}).Model(&device).Related(&alarms).Error; err != nil {
+	if err := ag.db.Model(&device).Related(&alarms).Error; err != nil {
 		return nil, err
 	}
-	fmt.Print(alarms)
 	return alarms, nil
 }
 
 func (ag *alarmGorm) ByID(id uint, ctx context.Context) (*Alarm, error) {
 	var alarm Alarm
-	if err := ag.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}).Where("id = ?", id).First(&alarm).Error; err != nil {
+	if err := ag.db.Where("id = ?", id).First(&alarm).Error; err != nil {
 		return nil, err
 	}
 
@@ -78,18 +145,51 @@ func (ag *alarmGorm) ByID(id uint, ctx context.Context) (*Alarm, error) {
 func (ag *alarmGorm) Many(count int, ctx context.Context) ([]*Alarm, error) {
 
 	var alarms []*Alarm
-	if err := ag.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}).Limit(count).Find(&alarms).Error; err != nil {
+	if err := ag.db.Limit(count).Find(&alarms).Error; err != nil {
 		return nil, err
 	}
 	return alarms, nil
 }
 
 func (ag *alarmGorm) Create(alarm *Alarm, ctx context.Context) error {
-	return ag.db.Create(alarm).Error
+	now := time.Now()
+	var upserted struct {
+		Alarm
+		Resurfaced bool
+	}
+	err := ag.db.Raw(alarmUpsert, alarm.DeviceID, alarm.Type,
+		now, now, alarm.Type, alarm.Status, alarm.Severity, alarm.DeviceID, alarm.RuleID, alarm.Suppressed, now, now).
+		Scan(&upserted).Error
+	if err != nil {
+		return err
+	}
+	*alarm = upserted.Alarm
+	alarm.resurfaced = upserted.Resurfaced
+	return nil
+}
+
+// repeated reports whether a create counted another occurrence of an open
+// alarm, rather than raising a new one.
+func (a *Alarm) repeated() bool {
+	return a.Count > 1
+}
+
+// raised reports whether a create raised an alarm that webhooks,
+// notifications and escalation should hear about: a new alarm outside
+// maintenance, or an open one repeating after its maintenance ended.
+func (a *Alarm) raised() bool {
+	return !a.Suppressed && (!a.repeated() || a.resurfaced)
 }
 
+// alarmLifecycleColumns are only written by Transition.
+var alarmLifecycleColumns = []string{
+	"status", "acknowledged_by", "acknowledged_at", "acknowledge_comment", "cleared_by", "cleared_at", "clear_comment",
+}
+
+// Update leaves the lifecycle alone, so it can't write back a status that a
+// concurrent Transition has moved on from.
 func (ag *alarmGorm) Update(alarm *Alarm, ctx context.Context) error {
-	return ag.db.Save(alarm).Error
+	return ag.db.Omit(alarmLifecycleColumns...).Save(alarm).Error
 }
 func (ag *alarmGorm) Delete(id uint, ctx context.Context) error {
 	alarm := Alarm{Model: gorm.Model{ID: id}}
@@ -98,7 +198,8 @@ func (ag *alarmGorm) Delete(id uint, ctx context.Context) error {
 
 func (aw *alarmWebhook) Create(alarm *Alarm, ctx context.Context) error {
 	err := aw.AlarmDB.Create(alarm, ctx)
-	if err != nil {
+	// A repeat only counts another occurrence, subscribers hear once per alarm.
+	if err != nil || !alarm.raised() {
 		return err
 	}
 
@@ -112,7 +213,7 @@ func (aw *alarmWebhook) Create(alarm *Alarm, ctx context.Context) error {
 
 func (aw *alarmWebhook) Update(alarm *Alarm, ctx context.Context) error {
 	err := aw.AlarmDB.Update(alarm, ctx)
-	if err != nil {
+	if err != nil || alarm.Suppressed {
 		return err
 	}
 
@@ -129,7 +230,7 @@ func (aw *alarmWebhook) Delete(id uint, ctx context.Context) error {
 		return err
 	}
 	err = aw.AlarmDB.Delete(id, ctx)
-	if err != nil {
+	if err != nil || alarm.Suppressed {
 		return err
 	}
 
@@ -189,3 +290,19 @@ func (aa alarmAuthorization) Many(count int, ctx context.Context) ([]*Alarm, err
 	}
 	return aa.AlarmDB.Many(count, ctx)
 }
+func (aa alarmAuthorization) Query(query *AlarmQuery, ctx context.Context) (*AlarmPage, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return aa.AlarmDB.Query(query, ctx)
+}
+func (aa alarmAuthorization) Summary(query *AlarmQuery, ctx context.Context) (*AlarmSummary, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return aa.AlarmDB.Summary(query, ctx)
+}
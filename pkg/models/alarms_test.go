@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestAlarmCreateReadsUpsertedAlarm(t *testing.T) {
+	cases := []struct {
+		name                  string
+		count                 int64
+		suppressed, resurface bool
+		repeated, raised      bool
+	}{
+		{"new", 1, false, false, false, true},
+		{"new in maintenance", 1, true, false, false, false},
+		{"repeat", 2, false, false, true, false},
+		{"repeat in maintenance", 2, true, false, true, false},
+		{"repeat after maintenance", 3, false, true, true, true},
+	}
+	for _, c := range cases {
+		db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+			if strings.Contains(query, "INSERT INTO alarms") {
+				return fakeResult{
+					Columns: []string{"id", "type", "status", "count", "suppressed", "resurfaced"},
+					Rows:    [][]driver.Value{{int64(4), "temperature", AlarmActive, c.count, c.suppressed, c.resurface}},
+				}
+			}
+			return fakeResult{}
+		})
+
+		alarm := &Alarm{Type: "temperature", Status: AlarmActive, DeviceID: 1, Suppressed: !c.suppressed}
+		if err := (&alarmGorm{db: db}).Create(alarm, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		// The stored flag wins over the one asked for.
+		if alarm.ID != 4 || alarm.Suppressed != c.suppressed {
+			t.Errorf("%s: read back alarm %d suppressed %v", c.name, alarm.ID, alarm.Suppressed)
+		}
+		if alarm.repeated() != c.repeated || alarm.raised() != c.raised {
+			t.Errorf("%s: repeated %v raised %v, want %v %v", c.name, alarm.repeated(), alarm.raised(), c.repeated, c.raised)
+		}
+
+		upsert := fake.Calls("INSERT INTO alarms")[0]
+		if !strings.Contains(upsert.Query, "suppressed = EXCLUDED.suppressed") {
+			t.Errorf("upsert keeps the stored suppressed flag: %q", upsert.Query)
+		}
+		if upsert.Args[0] != int64(1) || upsert.Args[1] != "temperature" {
+			t.Errorf("looked for the open alarm with %v", upsert.Args[:2])
+		}
+	}
+}
+
+// Reads must not open transactions they never finish, each one holds a
+// pooled connection until the process exits.
+func TestAlarmReadsDontOpenTransactions(t *testing.T) {
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, `FROM "alarms"`) {
+			return fakeResult{Columns: []string{"id", "device_id"}, Rows: [][]driver.Value{{int64(4), int64(1)}}}
+		}
+		return fakeResult{}
+	})
+	ag := &alarmGorm{db: db}
+
+	if _, err := ag.ByID(4, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ag.Many(10, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ag.ByDevice(1, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fake.Calls(`FROM "alarms"`)); n != 3 {
+		t.Errorf("read alarms %d times, want 3", n)
+	}
+	if indexOf(fake.Queries(), "BEGIN") >= 0 {
+		t.Errorf("reads began a transaction: %q", fake.Queries())
+	}
+}
+
+// stubUpserts answers creates as the upsert would for an alarm seen count times.
+type stubUpserts struct {
+	AlarmDB
+	count      uint
+	suppressed bool
+	resurfaced bool
+}
+
+func (su *stubUpserts) Create(alarm *Alarm, ctx context.Context) error {
+	alarm.Count = su.count
+	alarm.Suppressed = su.suppressed
+	alarm.resurfaced = su.resurfaced
+	return nil
+}
+
+// recordingWebhooks records the actions sent to subscribers.
+type recordingWebhooks struct {
+	SubscriptionService
+	actions []string
+}
+
+func (rw *recordingWebhooks) Webhook(deviceID uint, action, Type string, data interface{}) error {
+	rw.actions = append(rw.actions, action)
+	return nil
+}
+
+func TestAlarmWebhookSkipsRepeats(t *testing.T) {
+	cases := []struct {
+		name   string
+		upsert stubUpserts
+		want   []string
+	}{
+		{"new", stubUpserts{count: 1}, []string{"CREATE"}},
+		{"new in maintenance", stubUpserts{count: 1, suppressed: true}, nil},
+		{"repeat", stubUpserts{count: 2}, nil},
+		{"repeat after maintenance", stubUpserts{count: 3, resurfaced: true}, []string{"CREATE"}},
+	}
+	for _, c := range cases {
+		hooks := &recordingWebhooks{}
+		aw := &alarmWebhook{AlarmDB: &c.upsert, Subscription: hooks}
+		if err := aw.Create(&Alarm{DeviceID: 1, Type: "temperature"}, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(hooks.actions, ",") != strings.Join(c.want, ",") {
+			t.Errorf("%s: sent %v, want %v", c.name, hooks.actions, c.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/streadway/amqp"
@@ -14,6 +15,25 @@ type Device struct {
 	IMEI      string  `json:"imei"`
 	Longitude float64 `json:"longitude"`
 	Latitude  float64 `json:"latitude"`
+	Group     string  `gorm:"index" json:"group"`
+
+	// Zero uses the default heartbeat interval.
+	HeartbeatSeconds uint       `json:"heartbeatSeconds"`
+	LastSeenAt       *time.Time `json:"lastSeenAt"`
+	Status           string     `gorm:"index" json:"status"`
+	OfflineAlarmID   *uint      `json:"-"`
+}
+
+// DeviceQuery filters a page of devices, Name matches part of the name.
+type DeviceQuery struct {
+	PageQuery
+	Name   string
+	Status string
+}
+
+type DevicePage struct {
+	Devices []*Device `json:"devices"`
+	Next    string    `json:"next,omitempty"`
 }
 
 type deviceGorm struct {
@@ -39,6 +59,7 @@ type DeviceDB interface {
 	ByID(id uint, ctx context.Context) (*Device, error)
 	SearchByName(name string, ctx context.Context) ([]*Device, error)
 	Many(count int, ctx context.Context) ([]*Device, error)
+	Query(query *DeviceQuery, ctx context.Context) (*DevicePage, error)
 
 	//Mutators
 	Create(device *Device, ctx context.Context) error
@@ -107,6 +128,32 @@ func (dg *deviceGorm) Many(count int, ctx context.Context) ([]*Device, error) {
 	return devices, nil
 }
 
+func (dg *deviceGorm) Query(query *DeviceQuery, ctx context.Context) (*DevicePage, error) {
+	db := dg.db
+	if query.Name != "" {
+		db = db.Where("name LIKE ?", "%"+query.Name+"%")
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	db, err := query.scope(db)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := []*Device{}
+	if err := db.Find(&devices).Error; err != nil {
+		return nil, err
+	}
+
+	page := &DevicePage{Devices: devices}
+	if n, more := query.more(len(devices)); more {
+		page.Devices = devices[:n]
+		page.Next = encodeIDCursor(devices[n-1].ID)
+	}
+	return page, nil
+}
+
 func (dg *deviceGorm) ByName(name string, ctx context.Context) (*Device, error) {
 
 	var device Device
@@ -140,12 +187,14 @@ func (dg *deviceGorm) SearchByName(name string, ctx context.Context) ([]*Device,
 
 //Mutators
 func (dg *deviceGorm) Create(device *Device, ctx context.Context) (err error) {
+	// Connectivity is only ever set by the heartbeat monitor.
+	device.LastSeenAt, device.Status, device.OfflineAlarmID = nil, "", nil
 	err = dg.db.Create(device).Error
 	return
 }
 
 func (dg *deviceGorm) Update(device *Device, ctx context.Context) (err error) {
-	err = dg.db.Save(device).Error
+	err = dg.db.Omit(heartbeatColumns...).Save(device).Error
 	return
 }
 
@@ -281,6 +330,14 @@ func (da *deviceAuditLogger) Many(count int, ctx context.Context) ([]*Device, er
 	LogGet(uc.UserID, "Devices")
 	return da.DeviceDB.Many(count, ctx)
 }
+func (da *deviceAuditLogger) Query(query *DeviceQuery, ctx context.Context) (*DevicePage, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "Devices")
+	return da.DeviceDB.Query(query, ctx)
+}
 
 //Mutators
 func (da *deviceAuditLogger) Create(device *Device, ctx context.Context) error {
@@ -312,7 +369,7 @@ func (da deviceAuthorization) ByName(name string, ctx context.Context) (*Device,
 	uc, err := ExtractUserClaims(ctx)
 	dr := uc.Role.Devices
 	if err != nil || dr < 1 {
-		return nil, err
+		return nil, ErrDeviceReadRequired
 	}
 	return da.DeviceDB.ByName(name, ctx)
 }
@@ -340,6 +397,14 @@ func (da deviceAuthorization) Many(count int, ctx context.Context) ([]*Device, e
 	}
 	return da.DeviceDB.Many(count, ctx)
 }
+func (da deviceAuthorization) Query(query *DeviceQuery, ctx context.Context) (*DevicePage, error) {
+	uc, err := ExtractUserClaims(ctx)
+	dr := uc.Role.Devices
+	if err != nil || dr < 1 {
+		return nil, ErrDeviceReadRequired
+	}
+	return da.DeviceDB.Query(query, ctx)
+}
 
 //Mutators
 func (da deviceAuthorization) Create(device *Device, ctx context.Context) error {
@@ -3,6 +3,7 @@ package models
 type ErrorUnauthorized string
 type ErrorNotFound string
 type ErrorBadRequest string
+type ErrorConflict string
 
 func (e ErrorUnauthorized) Error() string {
 	return string(e)
@@ -16,6 +17,10 @@ func (e ErrorBadRequest) Error() string {
 	return string(e)
 }
 
+func (e ErrorConflict) Error() string {
+	return string(e)
+}
+
 const (
 	// Read Required
 	ErrDeviceReadRequired        = ErrorUnauthorized("Read Device Access Required")
@@ -47,4 +52,75 @@ const (
 
 	// ID Required
 	ErrInvalidID = ErrorBadRequest("ID Required")
+
+	ErrDeviceIDRequired    = ErrorBadRequest("Device ID Required")
+	ErrInvalidDeviceStatus = ErrorBadRequest("Status must be ONLINE or OFFLINE")
+	ErrObservedInFuture    = ErrorBadRequest("Observation Time Too Far In The Future")
+	ErrValueNotFinite      = ErrorBadRequest("Value must be a finite number")
+
+	// Batches
+	ErrBatchTooLarge = ErrorBadRequest("Batch Too Large")
+	ErrBatchEmpty    = ErrorBadRequest("Batch Empty")
+
+	// Imports
+	ErrImportHeader       = ErrorBadRequest("Import Header Row Required")
+	ErrDeviceNameRequired = ErrorBadRequest("Device Name Required")
+	ErrInvalidValue       = ErrorBadRequest("Invalid Value")
+	ErrInvalidTimestamp   = ErrorBadRequest("Invalid Timestamp")
+	ErrInvalidPrecision   = ErrorBadRequest("Precision must be one of ns, us, ms, s, m or h")
+
+	// Idempotency
+	ErrIdempotencyInFlight = ErrorConflict("A Request With This Idempotency Key Is Still In Progress")
+
+	// Query Parameters
+	ErrInvalidTimeRange = ErrorBadRequest("Invalid Time Range")
+	ErrInvalidOrder     = ErrorBadRequest("Order must be asc or desc")
+	ErrInvalidCursor    = ErrorBadRequest("Invalid Cursor")
+	ErrInvalidLimit     = ErrorBadRequest("Invalid Limit")
+	ErrInvalidFormat    = ErrorBadRequest("Format must be csv or ndjson")
+	ErrInvalidSort      = ErrorBadRequest("Sort must be one of id, created, updated, lastSeen, severity or count")
+
+	// Aggregation
+	ErrTypeRequired     = ErrorBadRequest("Measurement Type Required")
+	ErrInvalidBucket    = ErrorBadRequest("Bucket must be one of 1m, 5m, 1h or 1d")
+	ErrInvalidAggregate = ErrorBadRequest("Function must be one of min, max, avg, sum, count, first or last")
+	ErrInvalidFill      = ErrorBadRequest("Fill must be none or null")
+
+	// Alarms
+	ErrInvalidAlarmStatus    = ErrorBadRequest("Status must be ACTIVE, ACKNOWLEDGED or CLEARED")
+	ErrInvalidSeverity       = ErrorBadRequest("Severity must be MINOR, MAJOR or SEVERE")
+	ErrInvalidTransition     = ErrorBadRequest("Invalid Alarm Status Transition")
+	ErrStatusNeedsTransition = ErrorBadRequest("Alarm Status Can Only Change Through A Transition")
+	ErrAlarmTypeRequired     = ErrorBadRequest("Alarm Type Required")
+	ErrAlarmNotFound         = ErrorNotFound("Alarm Not Found")
+
+	// Comments
+	ErrCommentBodyRequired = ErrorBadRequest("Comment Body Required")
+
+	// Rules
+	ErrRuleNameRequired = ErrorBadRequest("Rule Name Required")
+	ErrRuleScope        = ErrorBadRequest("Rule may target a device or a group, not both")
+	ErrInvalidCondition = ErrorBadRequest("Condition must be one of gt, lt, range or rate")
+	ErrInvalidRuleRange = ErrorBadRequest("Range must have a low below its high")
+	ErrInvalidRuleRate  = ErrorBadRequest("Rate threshold must be above zero")
+
+	// Notifications
+	ErrChannelNameRequired  = ErrorBadRequest("Channel Name Required")
+	ErrChannelRequired      = ErrorBadRequest("Route must name an existing channel")
+	ErrInvalidChannelKind   = ErrorBadRequest("Kind must be email, chat or http")
+	ErrInvalidChannelTarget = ErrorBadRequest("Target must be email addresses for email, or an http URL")
+
+	// Escalations
+	ErrPolicyNameRequired    = ErrorBadRequest("Policy Name Required")
+	ErrPolicyTarget          = ErrorBadRequest("Policy must apply to a rule or a severity")
+	ErrStepsRequired         = ErrorBadRequest("Policy Steps Required")
+	ErrStepRecipientRequired = ErrorBadRequest("Each step needs users or channels to notify")
+
+	// Maintenance
+	ErrWindowNameRequired    = ErrorBadRequest("Maintenance Window Name Required")
+	ErrWindowScope           = ErrorBadRequest("Maintenance window may target a device or a group, not both")
+	ErrInvalidWindow         = ErrorBadRequest("Window needs a start before its end, or a schedule, not both")
+	ErrInvalidWindowDuration = ErrorBadRequest("Duration must be between one minute and seven days")
+	ErrInvalidTimezone       = ErrorBadRequest("Invalid Timezone")
+	ErrInvalidSuppressed     = ErrorBadRequest("Suppressed must be true or false")
 )
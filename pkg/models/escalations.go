@@ -0,0 +1,552 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// NotifyEscalated is the notification action of an escalation step.
+const NotifyEscalated = "ESCALATED"
+
+const DefaultEscalationInterval = 30 * time.Second
+
+// EscalationPolicy notifies ever wider tiers of recipients while an alarm
+// stays unacknowledged. A policy applies to the alarms raised by a rule, or
+// failing that to alarms of a severity. The most specific policy wins.
+type EscalationPolicy struct {
+	gorm.Model
+	Name     string           `gorm:"not null" json:"name"`
+	RuleID   uint             `gorm:"index" json:"ruleId"`
+	Severity string           `gorm:"index" json:"severity"`
+	Steps    []EscalationStep `gorm:"foreignkey:PolicyID" json:"steps"`
+}
+
+// EscalationStep is one tier of a policy. It fires DelayMinutes after the
+// alarm was raised for the first step, or after the step before it.
+type EscalationStep struct {
+	ID           uint          `gorm:"primary_key" json:"id"`
+	PolicyID     uint          `gorm:"not null;index" json:"policyId"`
+	Position     int           `gorm:"not null" json:"position"`
+	DelayMinutes uint          `json:"delayMinutes"`
+	UserIDs      pq.Int64Array `gorm:"type:integer[]" json:"userIds"`
+	ChannelIDs   pq.Int64Array `gorm:"type:integer[]" json:"channelIds"`
+}
+
+func (step *EscalationStep) delay() time.Duration {
+	return time.Duration(step.DelayMinutes) * time.Minute
+}
+
+// Escalation is an alarm waiting for its next step. It is stored rather than
+// held in memory, so pending escalations outlive a restart.
+type Escalation struct {
+	ID       uint      `gorm:"primary_key"`
+	AlarmID  uint      `gorm:"not null;unique_index"`
+	PolicyID uint      `gorm:"not null"`
+	Position int       `gorm:"not null"`
+	Level    int       `gorm:"not null"`
+	DueAt    time.Time `gorm:"not null;index"`
+}
+
+type escalationGorm struct {
+	db *gorm.DB
+}
+
+type escalationValidator struct {
+	EscalationDB
+}
+
+type escalationAuditLogger struct {
+	EscalationDB
+}
+
+type escalationAuthorization struct {
+	EscalationDB
+}
+
+type policyValFunc func(*EscalationPolicy) error
+
+type EscalationService interface {
+	EscalationDB
+}
+
+type EscalationDB interface {
+	ByID(id uint, ctx context.Context) (*EscalationPolicy, error)
+	Many(ctx context.Context) ([]*EscalationPolicy, error)
+	Create(policy *EscalationPolicy, ctx context.Context) error
+	Update(policy *EscalationPolicy, ctx context.Context) error
+	Delete(id uint, ctx context.Context) error
+}
+
+func NewEscalationService(db *gorm.DB) EscalationService {
+	return &escalationAuthorization{
+		&escalationAuditLogger{
+			&escalationValidator{
+				&escalationGorm{
+					db: db,
+				},
+			},
+		},
+	}
+}
+
+func orderedSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+func (eg *escalationGorm) ByID(id uint, ctx context.Context) (*EscalationPolicy, error) {
+	var policy EscalationPolicy
+	if err := eg.db.Preload("Steps", orderedSteps).Where("id = ?", id).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (eg *escalationGorm) Many(ctx context.Context) ([]*EscalationPolicy, error) {
+	policies := []*EscalationPolicy{}
+	if err := eg.db.Preload("Steps", orderedSteps).Order("id").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (eg *escalationGorm) Create(policy *EscalationPolicy, ctx context.Context) error {
+	return eg.db.Create(policy).Error
+}
+
+// The policy's steps are replaced by those given.
+func (eg *escalationGorm) Update(policy *EscalationPolicy, ctx context.Context) error {
+	tx := eg.db.Begin()
+	if err := tx.Where("policy_id = ?", policy.ID).Delete(&EscalationStep{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for i := range policy.Steps {
+		policy.Steps[i].ID = 0
+	}
+	if err := tx.Save(policy).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Alarms waiting on the policy stop escalating.
+func (eg *escalationGorm) Delete(id uint, ctx context.Context) error {
+	tx := eg.db.Begin()
+	policy := EscalationPolicy{Model: gorm.Model{ID: id}}
+	if err := tx.Delete(&policy).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("policy_id = ?", id).Delete(&EscalationStep{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("policy_id = ?", id).Delete(&Escalation{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (ev *escalationValidator) Create(policy *EscalationPolicy, ctx context.Context) error {
+	if err := ev.runPolicyValFns(policy, ev.hasName, ev.oneTarget, ev.hasSteps); err != nil {
+		return err
+	}
+	return ev.EscalationDB.Create(policy, ctx)
+}
+
+func (ev *escalationValidator) Update(policy *EscalationPolicy, ctx context.Context) error {
+	if err := ev.runPolicyValFns(policy, ev.hasName, ev.oneTarget, ev.hasSteps); err != nil {
+		return err
+	}
+	return ev.EscalationDB.Update(policy, ctx)
+}
+
+func (ev *escalationValidator) runPolicyValFns(policy *EscalationPolicy, fns ...policyValFunc) error {
+	for _, fn := range fns {
+		if err := fn(policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ev *escalationValidator) hasName(policy *EscalationPolicy) error {
+	if policy.Name == "" {
+		return ErrPolicyNameRequired
+	}
+	return nil
+}
+
+// A policy applies to a rule or a severity, never both.
+func (ev *escalationValidator) oneTarget(policy *EscalationPolicy) error {
+	if (policy.RuleID == 0) == (policy.Severity == "") {
+		return ErrPolicyTarget
+	}
+	if policy.Severity != "" && !validSeverity(policy.Severity) {
+		return ErrInvalidSeverity
+	}
+	return nil
+}
+
+func (ev *escalationValidator) hasSteps(policy *EscalationPolicy) error {
+	if len(policy.Steps) == 0 {
+		return ErrStepsRequired
+	}
+	for i := range policy.Steps {
+		step := &policy.Steps[i]
+		if len(step.UserIDs) == 0 && len(step.ChannelIDs) == 0 {
+			return ErrStepRecipientRequired
+		}
+		step.PolicyID = policy.ID
+	}
+	return nil
+}
+
+func (ea *escalationAuditLogger) ByID(id uint, ctx context.Context) (*EscalationPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "EscalationPolicies")
+	return ea.EscalationDB.ByID(id, ctx)
+}
+
+func (ea *escalationAuditLogger) Many(ctx context.Context) ([]*EscalationPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "EscalationPolicies")
+	return ea.EscalationDB.Many(ctx)
+}
+
+func (ea *escalationAuditLogger) Create(policy *EscalationPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "EscalationPolicies")
+	return ea.EscalationDB.Create(policy, ctx)
+}
+
+func (ea *escalationAuditLogger) Update(policy *EscalationPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogUpdate(uc.UserID, "EscalationPolicies")
+	return ea.EscalationDB.Update(policy, ctx)
+}
+
+func (ea *escalationAuditLogger) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "EscalationPolicies")
+	return ea.EscalationDB.Delete(id, ctx)
+}
+
+// Escalation policies decide who hears about alarms, so they share the alarms
+// role.
+func (ea *escalationAuthorization) ByID(id uint, ctx context.Context) (*EscalationPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ea.EscalationDB.ByID(id, ctx)
+}
+func (ea *escalationAuthorization) Many(ctx context.Context) ([]*EscalationPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ea.EscalationDB.Many(ctx)
+}
+func (ea *escalationAuthorization) Create(policy *EscalationPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 2 {
+		return ErrAlarmsWriteRequired
+	}
+	return ea.EscalationDB.Create(policy, ctx)
+}
+func (ea *escalationAuthorization) Update(policy *EscalationPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 3 {
+		return ErrAlarmsUpdateRequired
+	}
+	return ea.EscalationDB.Update(policy, ctx)
+}
+func (ea *escalationAuthorization) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 4 {
+		return ErrAlarmsDeleteRequired
+	}
+	return ea.EscalationDB.Delete(id, ctx)
+}
+
+// Escalator schedules escalations for new alarms and runs the steps that are
+// due, until the alarm is acknowledged or cleared.
+type Escalator struct {
+	db       *gorm.DB
+	notifier *Notifier
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewEscalator(db *gorm.DB, notifier *Notifier, interval time.Duration) *Escalator {
+	if interval <= 0 {
+		interval = DefaultEscalationInterval
+	}
+	return &Escalator{
+		db:       db,
+		notifier: notifier,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (e *Escalator) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case now := <-ticker.C:
+				if err := e.Run(now); err != nil {
+					log.Println(err)
+				}
+			}
+		}
+	}()
+}
+
+func (e *Escalator) Stop() {
+	close(e.stop)
+	e.wg.Wait()
+}
+
+// policy finds the policy for the alarm, preferring one attached to the rule
+// that raised it.
+func (e *Escalator) policy(alarm *Alarm) (*EscalationPolicy, error) {
+	db := e.db.Preload("Steps", orderedSteps)
+	var policies []*EscalationPolicy
+	if alarm.RuleID != nil {
+		if err := db.Where("rule_id = ?", *alarm.RuleID).Order("id").Limit(1).Find(&policies).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(policies) == 0 {
+		if err := db.Where("rule_id = 0 AND severity = ?", alarm.Severity).Order("id").Limit(1).Find(&policies).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return policies[0], nil
+}
+
+// Schedule starts escalating a newly raised alarm, if a policy applies to it.
+// A nil escalator schedules nothing.
+func (e *Escalator) Schedule(alarm *Alarm) error {
+	if e == nil {
+		return nil
+	}
+
+	policy, err := e.policy(alarm)
+	if err != nil || policy == nil || len(policy.Steps) == 0 {
+		return err
+	}
+
+	first := policy.Steps[0]
+	escalation := &Escalation{
+		AlarmID:  alarm.ID,
+		PolicyID: policy.ID,
+		Position: first.Position,
+		Level:    1,
+		DueAt:    time.Now().Add(first.delay()),
+	}
+	return e.db.Create(escalation).Error
+}
+
+// Cancel stops escalating the alarm.
+func (e *Escalator) Cancel(alarmID uint) error {
+	if e == nil {
+		return nil
+	}
+	return e.db.Where("alarm_id = ?", alarmID).Delete(&Escalation{}).Error
+}
+
+// Run fires every step that is due. Each escalation is claimed and advanced
+// in its own transaction, and its step is sent once that has committed, so a
+// failure only ever retries the escalation it happened to. Escalations being
+// run elsewhere are skipped, so several servers can share the table.
+func (e *Escalator) Run(now time.Time) error {
+	var due []*Escalation
+	if err := e.db.Where("due_at <= ?", now).Order("due_at").Find(&due).Error; err != nil {
+		return err
+	}
+
+	var failed error
+	for _, escalation := range due {
+		step, data, err := e.advance(escalation.ID, now)
+		if err != nil {
+			log.Printf("Escalation of alarm %d: %v", escalation.AlarmID, err)
+			failed = err
+			continue
+		}
+		if step == nil {
+			continue
+		}
+		if err := e.notifier.Escalate(step, data); err != nil {
+			log.Printf("Escalation of alarm %d: %v", data.Alarm.ID, err)
+		}
+	}
+	return failed
+}
+
+// advance claims a due escalation and moves it on to its next step, or
+// finishes it. It returns the step to send, or nil if there is nothing to
+// send because another server claimed it or the alarm has been acknowledged,
+// cleared or deleted since the escalation was scheduled.
+func (e *Escalator) advance(id uint, now time.Time) (*EscalationStep, *NotificationData, error) {
+	tx := e.db.Begin()
+	step, data, err := e.fire(tx, id, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	return step, data, nil
+}
+
+func (e *Escalator) fire(tx *gorm.DB, id uint, now time.Time) (*EscalationStep, *NotificationData, error) {
+	var claimed []*Escalation
+	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").Where("id = ? AND due_at <= ?", id, now).Find(&claimed).Error
+	if err != nil || len(claimed) == 0 {
+		return nil, nil, err
+	}
+	escalation := claimed[0]
+
+	var alarm Alarm
+	err = tx.Where("id = ?", escalation.AlarmID).First(&alarm).Error
+	if gorm.IsRecordNotFoundError(err) || (err == nil && alarm.Status != AlarmActive) {
+		return nil, nil, tx.Delete(escalation).Error
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var steps []EscalationStep
+	if err := orderedSteps(tx).Where("policy_id = ? AND position >= ?", escalation.PolicyID, escalation.Position).Find(&steps).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(steps) == 0 {
+		return nil, nil, tx.Delete(escalation).Error
+	}
+
+	event := &AlarmEvent{
+		AlarmID: alarm.ID,
+		Action:  HistoryEscalated,
+		Comment: fmt.Sprintf("Level %d", escalation.Level),
+		At:      now,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return nil, nil, err
+	}
+
+	data := &NotificationData{Action: NotifyEscalated, Alarm: &alarm, Level: escalation.Level}
+	var next *EscalationStep
+	for i := range steps {
+		if steps[i].Position > steps[0].Position {
+			next = &steps[i]
+			break
+		}
+	}
+	if next == nil {
+		err = tx.Delete(escalation).Error
+	} else {
+		escalation.Position = next.Position
+		escalation.Level++
+		escalation.DueAt = now.Add(next.delay())
+		err = tx.Save(escalation).Error
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &steps[0], data, nil
+}
+
+// alarmEscalations starts escalating new alarms and stops once they are
+// acknowledged, cleared or deleted.
+type alarmEscalations struct {
+	AlarmDB
+	escalator *Escalator
+}
+
+func (ae *alarmEscalations) Create(alarm *Alarm, ctx context.Context) error {
+	if err := ae.AlarmDB.Create(alarm, ctx); err != nil {
+		return err
+	}
+	if !alarm.raised() {
+		return nil
+	}
+	if err := ae.escalator.Schedule(alarm); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+func (ae *alarmEscalations) Update(alarm *Alarm, ctx context.Context) error {
+	if err := ae.AlarmDB.Update(alarm, ctx); err != nil {
+		return err
+	}
+	if alarm.Status != AlarmActive {
+		if err := ae.escalator.Cancel(alarm.ID); err != nil {
+			log.Println(err)
+		}
+	}
+	return nil
+}
+
+func (ae *alarmEscalations) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	alarm, err := ae.AlarmDB.Transition(id, status, comment, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ae.escalator.Cancel(id); err != nil {
+		log.Println(err)
+	}
+	return alarm, nil
+}
+
+func (ae *alarmEscalations) Delete(id uint, ctx context.Context) error {
+	if err := ae.AlarmDB.Delete(id, ctx); err != nil {
+		return err
+	}
+	if err := ae.escalator.Cancel(id); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
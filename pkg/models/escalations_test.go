@@ -0,0 +1,170 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/naspinall/Hive/pkg/notify"
+)
+
+// recordingSender records what it is asked to send, and the statements the
+// database had seen by then.
+type recordingSender struct {
+	mu   sync.Mutex
+	fake *fakeDB
+	sent []recordedMessage
+	err  error
+}
+
+type recordedMessage struct {
+	Target string
+	Msg    *notify.Message
+	Seen   []string
+}
+
+func (rs *recordingSender) Send(target string, msg *notify.Message) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	var seen []string
+	if rs.fake != nil {
+		seen = rs.fake.Queries()
+	}
+	rs.sent = append(rs.sent, recordedMessage{Target: target, Msg: msg, Seen: seen})
+	return rs.err
+}
+
+func (rs *recordingSender) Sent() []recordedMessage {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]recordedMessage(nil), rs.sent...)
+}
+
+var escalationColumns = []string{"id", "alarm_id", "policy_id", "position", "level", "due_at"}
+
+// escalationResponder answers for two due escalations of active alarms, each
+// with a step paging its alarm's channel. Writes for the alarms in fail error.
+func escalationResponder(fail map[int64]bool) func(string, []driver.Value) fakeResult {
+	due := rollupNow.Add(-time.Minute)
+	rows := map[int64][]driver.Value{
+		1: {int64(1), int64(10), int64(1), int64(1), int64(1), due},
+		2: {int64(2), int64(20), int64(1), int64(1), int64(1), due},
+	}
+	return func(query string, args []driver.Value) fakeResult {
+		switch {
+		case strings.Contains(query, `FROM "escalations"`) && strings.Contains(query, "FOR UPDATE SKIP LOCKED"):
+			return fakeResult{Columns: escalationColumns, Rows: [][]driver.Value{rows[args[0].(int64)]}}
+		case strings.Contains(query, `FROM "escalations"`):
+			return fakeResult{Columns: escalationColumns, Rows: [][]driver.Value{rows[1], rows[2]}}
+		case strings.Contains(query, `FROM "alarms"`):
+			return fakeResult{
+				Columns: []string{"id", "device_id", "type", "severity", "status"},
+				Rows:    [][]driver.Value{{args[0], int64(1), "temperature", "CRITICAL", AlarmActive}},
+			}
+		case strings.Contains(query, `FROM "escalation_steps"`):
+			return fakeResult{
+				Columns: []string{"id", "policy_id", "position", "delay_minutes", "channel_ids"},
+				Rows:    [][]driver.Value{{int64(1), int64(1), int64(1), int64(0), "{1}"}, {int64(2), int64(1), int64(2), int64(15), "{1}"}},
+			}
+		case strings.Contains(query, `FROM "notification_channels"`):
+			return fakeResult{
+				Columns: []string{"id", "kind", "target", "subject"},
+				Rows:    [][]driver.Value{{int64(1), ChannelChat, "pager", "{{.Alarm.ID}} level {{.Level}}"}},
+			}
+		case strings.Contains(query, `INTO "alarm_events"`) && fail[args[0].(int64)]:
+			return fakeResult{Err: errors.New("disk full")}
+		case strings.Contains(query, `INTO "alarm_events"`):
+			return fakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(1)}}}
+		}
+		return fakeResult{}
+	}
+}
+
+func TestEscalatorSendsAfterCommitting(t *testing.T) {
+	db, fake := newFakeGorm(t, escalationResponder(nil))
+	sender := &recordingSender{fake: fake}
+	escalator := NewEscalator(db, NewNotifier(db, map[string]notify.Sender{ChannelChat: sender}), 0)
+
+	if err := escalator.Run(rollupNow); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := sender.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d pages, want 2", len(sent))
+	}
+	for i, alarm := range []string{"10", "20"} {
+		if want := alarm + " level 1"; sent[i].Msg.Subject != want {
+			t.Errorf("page %d subject %q, want %q", i, sent[i].Msg.Subject, want)
+		}
+		// Each page goes out once its escalation has moved on, with no
+		// transaction left open.
+		seen := sent[i].Seen
+		begun, committed := len(matching(seen, "BEGIN")), len(matching(seen, "COMMIT"))
+		if begun != i+1 || committed != i+1 {
+			t.Errorf("page %d sent after %d transactions began and %d committed, want %d", i, begun, committed, i+1)
+		}
+	}
+
+	saved := fake.Calls(`UPDATE "escalations"`)
+	if len(saved) != 2 {
+		t.Fatalf("advanced %d escalations, want 2", len(saved))
+	}
+	for _, call := range saved {
+		if !strings.Contains(call.Query, `"level"`) {
+			t.Errorf("advanced with %q", call.Query)
+		}
+	}
+}
+
+func TestEscalatorFailureKeepsOtherEscalations(t *testing.T) {
+	db, fake := newFakeGorm(t, escalationResponder(map[int64]bool{10: true}))
+	sender := &recordingSender{}
+	escalator := NewEscalator(db, NewNotifier(db, map[string]notify.Sender{ChannelChat: sender}), 0)
+
+	if err := escalator.Run(rollupNow); err == nil {
+		t.Fatal("run succeeded with a failing escalation")
+	}
+
+	// The first escalation is retried next tick without having paged, the
+	// second pages once and moves on regardless.
+	sent := sender.Sent()
+	if len(sent) != 1 || sent[0].Msg.Subject != "20 level 1" {
+		t.Fatalf("sent %v, want only alarm 20", sent)
+	}
+	queries := fake.Queries()
+	if n := len(matching(queries, "BEGIN")); n != 2 {
+		t.Errorf("began %d transactions, want 2", n)
+	}
+	if indexOf(queries, "ROLLBACK") > indexOf(queries, "COMMIT") {
+		t.Errorf("statements out of order: %q", queries)
+	}
+	if n := len(fake.Calls(`UPDATE "escalations"`)); n != 1 {
+		t.Errorf("advanced %d escalations, want 1", n)
+	}
+}
+
+func TestEscalatorSkipsClaimedEscalations(t *testing.T) {
+	respond := escalationResponder(nil)
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, "FOR UPDATE SKIP LOCKED") {
+			return fakeResult{Columns: escalationColumns}
+		}
+		return respond(query, args)
+	})
+	sender := &recordingSender{}
+	escalator := NewEscalator(db, NewNotifier(db, map[string]notify.Sender{ChannelChat: sender}), 0)
+
+	if err := escalator.Run(rollupNow); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.Sent()) != 0 {
+		t.Error("paged for escalations claimed elsewhere")
+	}
+	if len(fake.Calls(`FROM "alarms"`)) != 0 {
+		t.Error("read alarms of escalations claimed elsewhere")
+	}
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const DefaultEventBuffer = 256
+
+// ErrSlowConsumer is the error of a watcher that was disconnected because its
+// buffer filled up.
+var ErrSlowConsumer = errors.New("Watcher Too Slow, Events Dropped")
+
+// Event is a change to a measurement or an alarm, Action is CREATE, UPDATE or
+// DELETE as with webhooks.
+type Event struct {
+	Action      string
+	Measurement *Measurement
+	Alarm       *Alarm
+}
+
+// EventFilter picks the events a watcher receives, empty fields match every
+// event. Severity only applies to alarms.
+type EventFilter struct {
+	DeviceIDs []uint
+	Type      string
+	Severity  string
+}
+
+func (f *EventFilter) matches(e *Event) bool {
+	var deviceID uint
+	var eventType string
+	switch {
+	case e.Measurement != nil:
+		deviceID, eventType = e.Measurement.DeviceID, e.Measurement.Type
+	case e.Alarm != nil:
+		deviceID, eventType = e.Alarm.DeviceID, e.Alarm.Type
+		if f.Severity != "" && e.Alarm.Severity != f.Severity {
+			return false
+		}
+	}
+
+	if f.Type != "" && eventType != f.Type {
+		return false
+	}
+	if len(f.DeviceIDs) == 0 {
+		return true
+	}
+	for _, id := range f.DeviceIDs {
+		if id == deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// Watcher receives the events matching its filter until it is closed, or is
+// disconnected for falling behind.
+type Watcher struct {
+	hub    *EventHub
+	filter EventFilter
+	alarms bool
+	events chan Event
+	err    error
+}
+
+// Events is closed when the watcher stops, Err then says why.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Err is ErrSlowConsumer if the watcher was disconnected, and nil otherwise.
+func (w *Watcher) Err() error {
+	w.hub.mu.Lock()
+	defer w.hub.mu.Unlock()
+	return w.err
+}
+
+func (w *Watcher) Close() {
+	w.hub.remove(w, nil)
+}
+
+// EventHub fans measurement and alarm events out to in-process watchers.
+// Publishing never blocks, a watcher whose buffer is full is disconnected.
+type EventHub struct {
+	mu       sync.Mutex
+	watchers map[*Watcher]struct{}
+	buffer   int
+}
+
+func NewEventHub(buffer int) *EventHub {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	return &EventHub{
+		watchers: map[*Watcher]struct{}{},
+		buffer:   buffer,
+	}
+}
+
+// Publish is safe to call on a nil hub, services built without one don't
+// publish events.
+func (h *EventHub) Publish(e Event) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for w := range h.watchers {
+		if w.alarms != (e.Alarm != nil) || !w.filter.matches(&e) {
+			continue
+		}
+		select {
+		case w.events <- e:
+		default:
+			h.removeLocked(w, ErrSlowConsumer)
+		}
+	}
+}
+
+func (h *EventHub) watch(filter EventFilter, alarms bool) *Watcher {
+	w := &Watcher{
+		hub:    h,
+		filter: filter,
+		alarms: alarms,
+		events: make(chan Event, h.buffer),
+	}
+	h.mu.Lock()
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+	return w
+}
+
+func (h *EventHub) remove(w *Watcher, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(w, err)
+}
+
+func (h *EventHub) removeLocked(w *Watcher, err error) {
+	if _, ok := h.watchers[w]; !ok {
+		return
+	}
+	delete(h.watchers, w)
+	w.err = err
+	close(w.events)
+}
+
+// EventService watches live measurement and alarm events.
+type EventService interface {
+	WatchMeasurements(filter EventFilter, ctx context.Context) (*Watcher, error)
+	WatchAlarms(filter EventFilter, ctx context.Context) (*Watcher, error)
+}
+
+func NewEventService(hub *EventHub) EventService {
+	return &eventAuthorization{
+		EventService: &eventHub{hub: hub},
+	}
+}
+
+type eventHub struct {
+	hub *EventHub
+}
+
+func (eh *eventHub) WatchMeasurements(filter EventFilter, ctx context.Context) (*Watcher, error) {
+	return eh.hub.watch(filter, false), nil
+}
+
+func (eh *eventHub) WatchAlarms(filter EventFilter, ctx context.Context) (*Watcher, error) {
+	return eh.hub.watch(filter, true), nil
+}
+
+type eventAuthorization struct {
+	EventService
+}
+
+func (ea *eventAuthorization) WatchMeasurements(filter EventFilter, ctx context.Context) (*Watcher, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 1 {
+		return nil, ErrMeasurementReadRequired
+	}
+	return ea.EventService.WatchMeasurements(filter, ctx)
+}
+
+func (ea *eventAuthorization) WatchAlarms(filter EventFilter, ctx context.Context) (*Watcher, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ea.EventService.WatchAlarms(filter, ctx)
+}
+
+// Watchers are handed copies, so callers are free to keep using their
+// measurements and alarms after publishing them.
+func measurementEvent(action string, m *Measurement) Event {
+	measurement := *m
+	return Event{Action: action, Measurement: &measurement}
+}
+
+func alarmEvent(action string, a *Alarm) Event {
+	alarm := *a
+	return Event{Action: action, Alarm: &alarm}
+}
+
+// measurementEvents publishes every change to a measurement to the hub.
+type measurementEvents struct {
+	MeasurementDB
+	hub *EventHub
+}
+
+func (me *measurementEvents) Create(measurement *Measurement, ctx context.Context) error {
+	if err := me.MeasurementDB.Create(measurement, ctx); err != nil {
+		return err
+	}
+	me.hub.Publish(measurementEvent("CREATE", measurement))
+	return nil
+}
+
+func (me *measurementEvents) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	results, err := me.MeasurementDB.CreateBatch(measurements, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error == "" {
+			me.hub.Publish(measurementEvent("CREATE", measurements[result.Index]))
+		}
+	}
+	return results, nil
+}
+
+func (me *measurementEvents) Update(measurement *Measurement, ctx context.Context) error {
+	if err := me.MeasurementDB.Update(measurement, ctx); err != nil {
+		return err
+	}
+	me.hub.Publish(measurementEvent("UPDATE", measurement))
+	return nil
+}
+
+func (me *measurementEvents) Delete(id uint, ctx context.Context) error {
+	measurement, err := me.MeasurementDB.ByID(id, ctx)
+	if err != nil {
+		return err
+	}
+	if err := me.MeasurementDB.Delete(id, ctx); err != nil {
+		return err
+	}
+	me.hub.Publish(measurementEvent("DELETE", measurement))
+	return nil
+}
+
+// alarmEvents publishes every change to an alarm to the hub.
+type alarmEvents struct {
+	AlarmDB
+	hub *EventHub
+}
+
+func (ae *alarmEvents) Create(alarm *Alarm, ctx context.Context) error {
+	if err := ae.AlarmDB.Create(alarm, ctx); err != nil {
+		return err
+	}
+	if alarm.repeated() {
+		ae.hub.Publish(alarmEvent("UPDATE", alarm))
+		return nil
+	}
+	ae.hub.Publish(alarmEvent("CREATE", alarm))
+	return nil
+}
+
+func (ae *alarmEvents) Update(alarm *Alarm, ctx context.Context) error {
+	if err := ae.AlarmDB.Update(alarm, ctx); err != nil {
+		return err
+	}
+	ae.hub.Publish(alarmEvent("UPDATE", alarm))
+	return nil
+}
+
+func (ae *alarmEvents) Delete(id uint, ctx context.Context) error {
+	alarm, err := ae.AlarmDB.ByID(id, ctx)
+	if err != nil {
+		return err
+	}
+	if err := ae.AlarmDB.Delete(id, ctx); err != nil {
+		return err
+	}
+	ae.hub.Publish(alarmEvent("DELETE", alarm))
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// ExportQuery selects the measurements to export, an empty DeviceIDs exports
+// every device.
+type ExportQuery struct {
+	DeviceIDs []uint
+	Type      string
+	From      *time.Time
+	To        *time.Time
+}
+
+// Export calls fn for each matching measurement in observation order. Rows
+// are read from the database one at a time, so memory use doesn't grow with
+// the size of the export.
+func (mg *measurementGorm) Export(query *ExportQuery, ctx context.Context, fn func(*Measurement) error) error {
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return ErrInvalidTimeRange
+	}
+
+	db := mg.db.Model(&Measurement{})
+	if len(query.DeviceIDs) > 0 {
+		db = db.Where("device_id IN (?)", query.DeviceIDs)
+	}
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+	if query.From != nil {
+		db = db.Where("observed_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("observed_at < ?", *query.To)
+	}
+
+	rows, err := db.Order("device_id, observed_at, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var measurement Measurement
+		if err := mg.db.ScanRows(rows, &measurement); err != nil {
+			return err
+		}
+		if err := fn(&measurement); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (ma *measurementAuditLogger) Export(query *ExportQuery, ctx context.Context, fn func(*Measurement) error) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogGet(uc.UserID, "Measurements")
+	return ma.MeasurementDB.Export(query, ctx, fn)
+}
+
+func (ma *measurementAuthorization) Export(query *ExportQuery, ctx context.Context, fn func(*Measurement) error) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 1 {
+		return ErrMeasurementReadRequired
+	}
+	return ma.MeasurementDB.Export(query, ctx, fn)
+}
@@ -0,0 +1,235 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+// fakeDB is a database/sql driver that records every statement it is given
+// and answers from a function, so the SQL the models send can be checked
+// without a postgres server.
+type fakeDB struct {
+	mu      sync.Mutex
+	calls   []fakeCall
+	respond func(query string, args []driver.Value) fakeResult
+}
+
+type fakeCall struct {
+	Query string
+	Args  []driver.Value
+}
+
+type fakeResult struct {
+	Columns  []string
+	Rows     [][]driver.Value
+	Affected int64
+	Err      error
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// newFakeGorm opens a postgres flavoured gorm.DB on a fakeDB. Statements with
+// no answer from respond return no rows and affect one row.
+func newFakeGorm(t *testing.T, respond func(query string, args []driver.Value) fakeResult) (*gorm.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{respond: respond}
+	name := fmt.Sprintf("%s-%p", t.Name(), fake)
+
+	fakeDBsMu.Lock()
+	fakeDBs[name] = fake
+	fakeDBsMu.Unlock()
+
+	sqlDB, err := sql.Open("fakedb", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open("postgres", sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDBsMu.Lock()
+		delete(fakeDBs, name)
+		fakeDBsMu.Unlock()
+	})
+	return db, fake
+}
+
+func (f *fakeDB) run(query string, args []driver.Value) fakeResult {
+	f.mu.Lock()
+	f.calls = append(f.calls, fakeCall{Query: query, Args: args})
+	f.mu.Unlock()
+
+	if f.respond != nil {
+		if res := f.respond(query, args); res.Columns != nil || res.Err != nil || res.Affected != 0 {
+			return res
+		}
+	}
+	return fakeResult{Affected: 1}
+}
+
+// Calls returns the statements containing each of the fragments, in order.
+func (f *fakeDB) Calls(fragments ...string) []fakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []fakeCall
+	for _, call := range f.calls {
+		matched := true
+		for _, fragment := range fragments {
+			if !strings.Contains(call.Query, fragment) {
+				matched = false
+			}
+		}
+		if matched {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Queries returns every statement in order, with transactions shown as
+// BEGIN, COMMIT and ROLLBACK.
+func (f *fakeDB) Queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	queries := make([]string, len(f.calls))
+	for i, call := range f.calls {
+		queries[i] = call.Query
+	}
+	return queries
+}
+
+// matching returns the queries containing the fragment.
+func matching(queries []string, fragment string) []string {
+	var matched []string
+	for _, q := range queries {
+		if strings.Contains(q, fragment) {
+			matched = append(matched, q)
+		}
+	}
+	return matched
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	fake, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("fakedb: unknown database %q", name)
+	}
+	return &fakeConn{db: fake}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.run("BEGIN", nil)
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := c.db.run(query, values(args))
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return driver.RowsAffected(res.Affected), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := c.db.run(query, values(args))
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &fakeRows{columns: res.Columns, rows: res.Rows}, nil
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, arg := range args {
+		vs[i] = arg.Value
+	}
+	return vs
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.run("COMMIT", nil)
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.run("ROLLBACK", nil)
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res := s.conn.db.run(s.query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return driver.RowsAffected(res.Affected), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res := s.conn.db.run(s.query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &fakeRows{columns: res.Columns, rows: res.Rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,249 @@
+package models
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Device connectivity statuses, a device that has never been heard from has
+// no status.
+const (
+	DeviceOnline  = "ONLINE"
+	DeviceOffline = "OFFLINE"
+
+	OfflineAlarmType = "offline"
+)
+
+const (
+	DefaultHeartbeatInterval = 5 * time.Minute
+	DefaultHeartbeatCheck    = time.Minute
+)
+
+// heartbeatColumns are kept by the monitor, so device updates leave them be.
+var heartbeatColumns = []string{"last_seen_at", "status", "offline_alarm_id"}
+
+// Marks an online device as seen, late reports never move it backwards.
+const heartbeatTouch = `
+UPDATE devices SET last_seen_at = GREATEST(last_seen_at, ?)
+WHERE id = ? AND status = 'ONLINE'`
+
+// HeartbeatMonitor records when each device was last heard from, and marks
+// devices that have been quiet for longer than their heartbeat interval as
+// offline, raising an offline alarm that is cleared when they reconnect.
+type HeartbeatMonitor struct {
+	db       *gorm.DB
+	alarms   AlarmService
+	interval time.Duration
+	check    time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewHeartbeatMonitor checks devices every check, interval is the heartbeat
+// for devices that don't set their own.
+func NewHeartbeatMonitor(db *gorm.DB, alarms AlarmService, interval, check time.Duration) *HeartbeatMonitor {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	if check <= 0 {
+		check = DefaultHeartbeatCheck
+	}
+	return &HeartbeatMonitor{
+		db:       db,
+		alarms:   alarms,
+		interval: interval,
+		check:    check,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (hm *HeartbeatMonitor) Start() {
+	hm.wg.Add(1)
+	go func() {
+		defer hm.wg.Done()
+		ticker := time.NewTicker(hm.check)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hm.stop:
+				return
+			case now := <-ticker.C:
+				if err := hm.Run(now); err != nil {
+					log.Println(err)
+				}
+			}
+		}
+	}()
+}
+
+func (hm *HeartbeatMonitor) Stop() {
+	close(hm.stop)
+	hm.wg.Wait()
+}
+
+// Seen records that the device was heard from at. A device that was offline
+// or never seen comes online, and its offline alarm is cleared. A nil monitor
+// records nothing.
+func (hm *HeartbeatMonitor) Seen(deviceID uint, at time.Time) error {
+	if hm == nil {
+		return nil
+	}
+
+	touched := hm.db.Exec(heartbeatTouch, at, deviceID)
+	if touched.Error != nil || touched.RowsAffected == 1 {
+		return touched.Error
+	}
+
+	tx := hm.db.Begin()
+	var device Device
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", deviceID).First(&device).Error; err != nil {
+		tx.Rollback()
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	lastSeen := at
+	if device.LastSeenAt != nil && device.LastSeenAt.After(at) {
+		lastSeen = *device.LastSeenAt
+	}
+	alarmID := device.OfflineAlarmID
+	err := tx.Model(&device).UpdateColumns(map[string]interface{}{
+		"last_seen_at":     lastSeen,
+		"status":           DeviceOnline,
+		"offline_alarm_id": nil,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if alarmID != nil {
+		return hm.clear(*alarmID)
+	}
+	return nil
+}
+
+// Run marks every online device that has missed its heartbeat as offline.
+func (hm *HeartbeatMonitor) Run(now time.Time) error {
+	var devices []*Device
+	err := hm.db.Where("status = ?", DeviceOnline).
+		Where("last_seen_at + COALESCE(NULLIF(heartbeat_seconds, 0), ?) * interval '1 second' < ?", hm.interval.Seconds(), now).
+		Find(&devices).Error
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		if err := hm.offline(device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Only takes the device offline if it hasn't been heard from since it was
+// read, then raises the alarm. Should the device reconnect before the alarm
+// is saved against it, the alarm is cleared straight away.
+func (hm *HeartbeatMonitor) offline(device *Device) error {
+	marked := hm.db.Model(&Device{}).
+		Where("id = ? AND status = ? AND last_seen_at = ?", device.ID, DeviceOnline, device.LastSeenAt).
+		UpdateColumn("status", DeviceOffline)
+	if marked.Error != nil || marked.RowsAffected == 0 {
+		return marked.Error
+	}
+
+	alarm := &Alarm{
+		Type:     OfflineAlarmType,
+		Status:   AlarmActive,
+		Severity: SeverityMajor,
+		DeviceID: device.ID,
+	}
+	if err := hm.alarms.Create(alarm, systemContext()); err != nil {
+		return err
+	}
+
+	saved := hm.db.Model(&Device{}).
+		Where("id = ? AND status = ?", device.ID, DeviceOffline).
+		UpdateColumn("offline_alarm_id", alarm.ID)
+	if saved.Error != nil {
+		return saved.Error
+	}
+	if saved.RowsAffected == 0 {
+		return hm.clear(alarm.ID)
+	}
+	return nil
+}
+
+// The alarm may already have been cleared or deleted by a user.
+func (hm *HeartbeatMonitor) clear(alarmID uint) error {
+	_, err := hm.alarms.Transition(alarmID, AlarmCleared, "Device reconnected", systemContext())
+	if err != nil && err != ErrInvalidTransition && !gorm.IsRecordNotFoundError(err) {
+		return err
+	}
+	return nil
+}
+
+// measurementHeartbeat counts every stored measurement as the device being
+// heard from.
+type measurementHeartbeat struct {
+	MeasurementDB
+	heartbeat *HeartbeatMonitor
+}
+
+func (mh *measurementHeartbeat) Create(measurement *Measurement, ctx context.Context) error {
+	if err := mh.MeasurementDB.Create(measurement, ctx); err != nil {
+		return err
+	}
+	if err := mh.heartbeat.Seen(measurement.DeviceID, time.Now()); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+func (mh *measurementHeartbeat) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	results, err := mh.MeasurementDB.CreateBatch(measurements, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := map[uint]bool{}
+	for _, result := range results {
+		deviceID := measurements[result.Index].DeviceID
+		if result.Error != "" || seen[deviceID] {
+			continue
+		}
+		seen[deviceID] = true
+		if err := mh.heartbeat.Seen(deviceID, now); err != nil {
+			log.Println(err)
+		}
+	}
+	return results, nil
+}
+
+// alarmHeartbeat counts an alarm raised against a device as the device being
+// heard from. It wraps the finished alarm service, so alarms the monitor and
+// rules raise themselves don't count.
+type alarmHeartbeat struct {
+	AlarmDB
+	heartbeat *HeartbeatMonitor
+}
+
+func (ah *alarmHeartbeat) Create(alarm *Alarm, ctx context.Context) error {
+	if err := ah.AlarmDB.Create(alarm, ctx); err != nil {
+		return err
+	}
+	if err := ah.heartbeat.Seen(alarm.DeviceID, time.Now()); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// deviceTable stands in for the devices table, answering the statements the
+// heartbeat monitor makes.
+type deviceTable struct {
+	mu      sync.Mutex
+	devices map[int64]*Device
+}
+
+var deviceColumns = []string{"id", "heartbeat_seconds", "last_seen_at", "status", "offline_alarm_id"}
+
+var assignment = regexp.MustCompile(`"(\w+)" = \$(\d+)`)
+
+func (dt *deviceTable) row(d *Device) []driver.Value {
+	var lastSeen, alarmID driver.Value
+	if d.LastSeenAt != nil {
+		lastSeen = *d.LastSeenAt
+	}
+	if d.OfflineAlarmID != nil {
+		alarmID = int64(*d.OfflineAlarmID)
+	}
+	return []driver.Value{int64(d.ID), int64(d.HeartbeatSeconds), lastSeen, d.Status, alarmID}
+}
+
+func toInt(v driver.Value) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	}
+	return -1
+}
+
+func affected(ok bool) fakeResult {
+	if ok {
+		return fakeResult{Affected: 1}
+	}
+	return fakeResult{Columns: []string{}}
+}
+
+func (dt *deviceTable) respond(query string, args []driver.Value) fakeResult {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "COALESCE(NULLIF(heartbeat_seconds, 0), $2)"):
+		status, interval, now := args[0].(string), args[1].(float64), args[2].(time.Time)
+		res := fakeResult{Columns: deviceColumns}
+		for id := int64(1); id <= int64(len(dt.devices)); id++ {
+			d := dt.devices[id]
+			seconds := float64(d.HeartbeatSeconds)
+			if seconds == 0 {
+				seconds = interval
+			}
+			if d.Status == status && d.LastSeenAt != nil && d.LastSeenAt.Add(time.Duration(seconds)*time.Second).Before(now) {
+				res.Rows = append(res.Rows, dt.row(d))
+			}
+		}
+		return res
+	case strings.Contains(query, "FOR UPDATE"):
+		return fakeResult{Columns: deviceColumns, Rows: [][]driver.Value{dt.row(dt.devices[toInt(args[0])])}}
+	case strings.Contains(query, "GREATEST(last_seen_at"):
+		d := dt.devices[toInt(args[1])]
+		if d.Status != DeviceOnline {
+			return affected(false)
+		}
+		if at := args[0].(time.Time); d.LastSeenAt.Before(at) {
+			d.LastSeenAt = &at
+		}
+		return affected(true)
+	case strings.Contains(query, `UPDATE "devices" SET "status" = $1`):
+		d := dt.devices[toInt(args[1])]
+		if d.Status != args[2].(string) || !d.LastSeenAt.Equal(args[3].(time.Time)) {
+			return affected(false)
+		}
+		d.Status = args[0].(string)
+		return affected(true)
+	case strings.Contains(query, `UPDATE "devices" SET "offline_alarm_id" = $1`):
+		d := dt.devices[toInt(args[1])]
+		if d.Status != args[2].(string) {
+			return affected(false)
+		}
+		id := uint(toInt(args[0]))
+		d.OfflineAlarmID = &id
+		return affected(true)
+	case strings.Contains(query, `UPDATE "devices" SET`):
+		// Seen bringing a device online, the device's ID is the last argument.
+		d := dt.devices[toInt(args[len(args)-1])]
+		for _, m := range assignment.FindAllStringSubmatch(query[:strings.Index(query, "WHERE")], -1) {
+			n, _ := strconv.Atoi(m[2])
+			switch v := args[n-1]; m[1] {
+			case "status":
+				d.Status = v.(string)
+			case "last_seen_at":
+				at := v.(time.Time)
+				d.LastSeenAt = &at
+			case "offline_alarm_id":
+				d.OfflineAlarmID = nil
+				if v != nil {
+					id := uint(toInt(v))
+					d.OfflineAlarmID = &id
+				}
+			}
+		}
+		return affected(true)
+	}
+	return fakeResult{}
+}
+
+func heartbeatDevices() *deviceTable {
+	seen := func(ago time.Duration) *time.Time {
+		at := rollupNow.Add(-ago)
+		return &at
+	}
+	return &deviceTable{devices: map[int64]*Device{
+		// The default interval is five minutes.
+		1: {Model: gorm.Model{ID: 1}, Status: DeviceOnline, LastSeenAt: seen(6 * time.Minute)},
+		2: {Model: gorm.Model{ID: 2}, Status: DeviceOnline, LastSeenAt: seen(4 * time.Minute)},
+		// Devices with their own heartbeat.
+		3: {Model: gorm.Model{ID: 3}, Status: DeviceOnline, LastSeenAt: seen(2 * time.Minute), HeartbeatSeconds: 60},
+		4: {Model: gorm.Model{ID: 4}, Status: DeviceOnline, LastSeenAt: seen(6 * time.Minute), HeartbeatSeconds: 600},
+		// Already offline, and never seen.
+		5: {Model: gorm.Model{ID: 5}, Status: DeviceOffline, LastSeenAt: seen(time.Hour)},
+		6: {Model: gorm.Model{ID: 6}},
+	}}
+}
+
+func TestHeartbeatRunMarksQuietDevicesOffline(t *testing.T) {
+	devices := heartbeatDevices()
+	db, fake := newFakeGorm(t, devices.respond)
+	alarms := &recordingAlarms{}
+	hm := NewHeartbeatMonitor(db, alarms, 0, 0)
+
+	// A second run finds nothing new to raise.
+	for run := 0; run < 2; run++ {
+		if err := hm.Run(rollupNow); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listed := fake.Calls("COALESCE(NULLIF(heartbeat_seconds, 0)")
+	if len(listed) != 2 || listed[0].Args[1] != DefaultHeartbeatInterval.Seconds() {
+		t.Errorf("listed quiet devices with %+v, want the default interval", listed)
+	}
+
+	want := map[int64]string{1: DeviceOffline, 2: DeviceOnline, 3: DeviceOffline, 4: DeviceOnline, 5: DeviceOffline, 6: ""}
+	for id, status := range want {
+		if got := devices.devices[id].Status; got != status {
+			t.Errorf("device %d is %q, want %q", id, got, status)
+		}
+	}
+
+	if len(alarms.raised) != 2 {
+		t.Fatalf("raised %+v, want one alarm for each of devices 1 and 3", alarms.raised)
+	}
+	for i, deviceID := range []uint{1, 3} {
+		alarm := alarms.raised[i]
+		if alarm.DeviceID != deviceID || alarm.Type != OfflineAlarmType || alarm.Status != AlarmActive {
+			t.Errorf("raised %+v for device %d", alarm, deviceID)
+		}
+		if got := devices.devices[int64(deviceID)].OfflineAlarmID; got == nil || *got != alarm.ID {
+			t.Errorf("device %d offline alarm %v, want %d", deviceID, got, alarm.ID)
+		}
+	}
+}
+
+func TestHeartbeatReconnectClearsAlarm(t *testing.T) {
+	devices := heartbeatDevices()
+	db, _ := newFakeGorm(t, devices.respond)
+	alarms := &recordingAlarms{}
+	hm := NewHeartbeatMonitor(db, alarms, 0, 0)
+
+	if err := hm.Run(rollupNow); err != nil {
+		t.Fatal(err)
+	}
+	alarmID := *devices.devices[1].OfflineAlarmID
+
+	// Reporting again after reconnecting only moves last seen on.
+	for i := 1; i <= 2; i++ {
+		if err := hm.Seen(1, rollupNow.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	device := devices.devices[1]
+	if device.Status != DeviceOnline || device.OfflineAlarmID != nil {
+		t.Errorf("reconnected device is %q with offline alarm %v", device.Status, device.OfflineAlarmID)
+	}
+	if !device.LastSeenAt.Equal(rollupNow.Add(2 * time.Second)) {
+		t.Errorf("last seen %v", device.LastSeenAt)
+	}
+	if len(alarms.cleared) != 1 || alarms.cleared[0] != alarmID {
+		t.Errorf("cleared %v, want alarm %d once", alarms.cleared, alarmID)
+	}
+	if other := devices.devices[3]; other.Status != DeviceOffline || other.OfflineAlarmID == nil {
+		t.Errorf("device 3 is %q with offline alarm %v, want it left offline", other.Status, other.OfflineAlarmID)
+	}
+}
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Alarm history actions.
+const (
+	HistoryCreated      = "CREATED"
+	HistoryReoccurred   = "REOCCURRED"
+	HistoryAcknowledged = AlarmAcknowledged
+	HistoryCleared      = AlarmCleared
+	HistoryEscalated    = NotifyEscalated
+)
+
+// AlarmComment is a note left on an alarm while investigating it.
+type AlarmComment struct {
+	gorm.Model
+	AlarmID uint   `gorm:"not null;index" json:"alarmId"`
+	UserID  uint   `gorm:"not null" json:"userId"`
+	Body    string `gorm:"not null" json:"body"`
+}
+
+// AlarmEvent is an entry in an alarm's history. Events are only ever added,
+// never changed or removed. UserID is nil when the system acted, such as a
+// rule raising the alarm or an escalation firing.
+type AlarmEvent struct {
+	ID      uint      `gorm:"primary_key" json:"id"`
+	AlarmID uint      `gorm:"not null;index" json:"alarmId"`
+	Action  string    `gorm:"not null" json:"action"`
+	UserID  *uint     `json:"userId"`
+	Comment string    `json:"comment,omitempty"`
+	At      time.Time `gorm:"not null" json:"at"`
+}
+
+// actor is the user acting in ctx, or nil for the system.
+func actor(ctx context.Context) *uint {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil || uc.UserID == 0 {
+		return nil
+	}
+	id := uc.UserID
+	return &id
+}
+
+type historyGorm struct {
+	db *gorm.DB
+}
+
+type historyValidator struct {
+	HistoryDB
+	db *gorm.DB
+}
+
+type historyAuditLogger struct {
+	HistoryDB
+}
+
+type historyAuthorization struct {
+	HistoryDB
+}
+
+type commentValFunc func(*AlarmComment, context.Context) error
+
+type HistoryService interface {
+	HistoryDB
+}
+
+type HistoryDB interface {
+	Comments(alarmID uint, ctx context.Context) ([]*AlarmComment, error)
+	CreateComment(comment *AlarmComment, ctx context.Context) error
+	DeleteComment(alarmID, id uint, ctx context.Context) error
+	History(alarmID uint, ctx context.Context) ([]*AlarmEvent, error)
+}
+
+func NewHistoryService(db *gorm.DB) HistoryService {
+	return &historyAuthorization{
+		&historyAuditLogger{
+			&historyValidator{
+				db: db,
+				HistoryDB: &historyGorm{
+					db: db,
+				},
+			},
+		},
+	}
+}
+
+func (hg *historyGorm) Comments(alarmID uint, ctx context.Context) ([]*AlarmComment, error) {
+	comments := []*AlarmComment{}
+	if err := hg.db.Where("alarm_id = ?", alarmID).Order("id").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (hg *historyGorm) CreateComment(comment *AlarmComment, ctx context.Context) error {
+	return hg.db.Create(comment).Error
+}
+
+func (hg *historyGorm) DeleteComment(alarmID, id uint, ctx context.Context) error {
+	return hg.db.Where("alarm_id = ? AND id = ?", alarmID, id).Delete(&AlarmComment{}).Error
+}
+
+func (hg *historyGorm) History(alarmID uint, ctx context.Context) ([]*AlarmEvent, error) {
+	events := []*AlarmEvent{}
+	if err := hg.db.Where("alarm_id = ?", alarmID).Order("at, id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (hv *historyValidator) CreateComment(comment *AlarmComment, ctx context.Context) error {
+	if err := hv.runCommentValFns(comment, ctx, hv.hasBody, hv.alarmExists, hv.setAuthor); err != nil {
+		return err
+	}
+	return hv.HistoryDB.CreateComment(comment, ctx)
+}
+
+func (hv *historyValidator) runCommentValFns(comment *AlarmComment, ctx context.Context, fns ...commentValFunc) error {
+	for _, fn := range fns {
+		if err := fn(comment, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (hv *historyValidator) hasBody(comment *AlarmComment, ctx context.Context) error {
+	if comment.Body == "" {
+		return ErrCommentBodyRequired
+	}
+	return nil
+}
+
+func (hv *historyValidator) alarmExists(comment *AlarmComment, ctx context.Context) error {
+	var count int
+	if err := hv.db.Model(&Alarm{}).Where("id = ?", comment.AlarmID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrAlarmNotFound
+	}
+	return nil
+}
+
+// Comments are always left by the user making the request.
+func (hv *historyValidator) setAuthor(comment *AlarmComment, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	comment.UserID = uc.UserID
+	return nil
+}
+
+func (ha *historyAuditLogger) Comments(alarmID uint, ctx context.Context) ([]*AlarmComment, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "AlarmComments")
+	return ha.HistoryDB.Comments(alarmID, ctx)
+}
+
+func (ha *historyAuditLogger) CreateComment(comment *AlarmComment, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "AlarmComments")
+	return ha.HistoryDB.CreateComment(comment, ctx)
+}
+
+func (ha *historyAuditLogger) DeleteComment(alarmID, id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "AlarmComments")
+	return ha.HistoryDB.DeleteComment(alarmID, id, ctx)
+}
+
+func (ha *historyAuditLogger) History(alarmID uint, ctx context.Context) ([]*AlarmEvent, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "AlarmHistory")
+	return ha.HistoryDB.History(alarmID, ctx)
+}
+
+func (ha *historyAuthorization) Comments(alarmID uint, ctx context.Context) ([]*AlarmComment, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ha.HistoryDB.Comments(alarmID, ctx)
+}
+func (ha *historyAuthorization) CreateComment(comment *AlarmComment, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 2 {
+		return ErrAlarmsWriteRequired
+	}
+	return ha.HistoryDB.CreateComment(comment, ctx)
+}
+func (ha *historyAuthorization) DeleteComment(alarmID, id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 4 {
+		return ErrAlarmsDeleteRequired
+	}
+	return ha.HistoryDB.DeleteComment(alarmID, id, ctx)
+}
+func (ha *historyAuthorization) History(alarmID uint, ctx context.Context) ([]*AlarmEvent, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ha.HistoryDB.History(alarmID, ctx)
+}
+
+// alarmHistory records each change to an alarm's state in its history. It
+// sits below the validator, so only changes that were made are recorded.
+type alarmHistory struct {
+	AlarmDB
+	db *gorm.DB
+}
+
+func (ah *alarmHistory) record(alarmID uint, action, comment string, ctx context.Context) {
+	event := &AlarmEvent{
+		AlarmID: alarmID,
+		Action:  action,
+		UserID:  actor(ctx),
+		Comment: comment,
+		At:      time.Now(),
+	}
+	// The change has been made, so a failure is only logged.
+	if err := ah.db.Create(event).Error; err != nil {
+		log.Printf("History of alarm %d: %v", alarmID, err)
+	}
+}
+
+func (ah *alarmHistory) Create(alarm *Alarm, ctx context.Context) error {
+	if err := ah.AlarmDB.Create(alarm, ctx); err != nil {
+		return err
+	}
+	action := HistoryCreated
+	if alarm.repeated() {
+		action = HistoryReoccurred
+	}
+	ah.record(alarm.ID, action, "", ctx)
+	return nil
+}
+
+// An update only appears in the history when it moves the alarm's status.
+func (ah *alarmHistory) Update(alarm *Alarm, ctx context.Context) error {
+	previous, err := ah.AlarmDB.ByID(alarm.ID, ctx)
+	if err != nil {
+		return err
+	}
+	if err := ah.AlarmDB.Update(alarm, ctx); err != nil {
+		return err
+	}
+	if alarm.Status != previous.Status {
+		ah.record(alarm.ID, alarm.Status, "", ctx)
+	}
+	return nil
+}
+
+func (ah *alarmHistory) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	alarm, err := ah.AlarmDB.Transition(id, status, comment, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ah.record(alarm.ID, status, comment, ctx)
+	return alarm, nil
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// IdempotencyKey remembers which resource a client supplied key created, so
+// a retried request can be answered without creating it again.
+type IdempotencyKey struct {
+	ID         uint      `gorm:"primary_key"`
+	UserID     uint      `gorm:"unique_index:idx_idempotency_key"`
+	Resource   string    `gorm:"unique_index:idx_idempotency_key"`
+	Key        string    `gorm:"unique_index:idx_idempotency_key"`
+	ResourceID uint      // Zero while the first request is still in flight.
+	Results    string    `gorm:"type:text;not null;default:''"` // Batch results as JSON, empty while in flight.
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (ik *IdempotencyKey) inFlight() bool {
+	return ik.ResourceID == 0 && ik.Results == ""
+}
+
+type idempotencyContextKey string
+
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// IdempotencyReservationTimeout is how long a key may stay in flight before
+// the request holding it is assumed to have died and the key is freed.
+const IdempotencyReservationTimeout = 5 * time.Minute
+
+// WithIdempotencyKey attaches a client supplied key to ctx, creates made with
+// the same key inside the idempotency window return the original resource.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyContextKey("Key"), key)
+}
+
+func idempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyContextKey("Key")).(string)
+	return key
+}
+
+type idempotencyGorm struct {
+	db     *gorm.DB
+	window time.Duration
+}
+
+func newIdempotencyGorm(db *gorm.DB, window time.Duration) *idempotencyGorm {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	return &idempotencyGorm{db: db, window: window}
+}
+
+// Claims the key for the caller. If the key has already been used inside the
+// window the record it completed with is returned instead.
+func (ig *idempotencyGorm) reserve(userID uint, resource, key string) (*IdempotencyKey, *IdempotencyKey, error) {
+	now := time.Now()
+	expired := now.Add(-ig.window)
+	abandoned := now.Add(-IdempotencyReservationTimeout)
+	err := ig.db.Where("created_at < ? OR (resource_id = 0 AND results = '' AND created_at < ?)", expired, abandoned).
+		Delete(&IdempotencyKey{}).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record := &IdempotencyKey{UserID: userID, Resource: resource, Key: key}
+	if err := ig.db.Create(record).Error; err == nil {
+		return record, nil, nil
+	}
+
+	// Insert failed on the unique index, so the key has been seen before.
+	var existing IdempotencyKey
+	err = ig.db.Where("user_id = ? AND resource = ? AND key = ?", userID, resource, key).First(&existing).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing.inFlight() {
+		return nil, nil, ErrIdempotencyInFlight
+	}
+	return nil, &existing, nil
+}
+
+func (ig *idempotencyGorm) complete(record *IdempotencyKey, resourceID uint) error {
+	return ig.db.Model(record).Update("resource_id", resourceID).Error
+}
+
+func (ig *idempotencyGorm) completeBatch(record *IdempotencyKey, results []BatchResult) error {
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return ig.db.Model(record).Update("results", string(encoded)).Error
+}
+
+// Frees the key when the create failed so the client can retry with it.
+func (ig *idempotencyGorm) release(record *IdempotencyKey) error {
+	return ig.db.Delete(record).Error
+}
+
+type measurementIdempotency struct {
+	MeasurementDB
+	keys *idempotencyGorm
+}
+
+func (mi *measurementIdempotency) Create(measurement *Measurement, ctx context.Context) error {
+	key := idempotencyKeyFrom(ctx)
+	if key == "" {
+		return mi.MeasurementDB.Create(measurement, ctx)
+	}
+
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+
+	record, existing, err := mi.keys.reserve(uc.UserID, "Measurements", key)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		original, err := mi.MeasurementDB.ByID(existing.ResourceID, ctx)
+		if err != nil {
+			return err
+		}
+		*measurement = *original
+		return nil
+	}
+
+	if err := mi.MeasurementDB.Create(measurement, ctx); err != nil {
+		mi.keys.release(record)
+		return err
+	}
+	return mi.keys.complete(record, measurement.ID)
+}
+
+// CreateBatch replays the stored results when the key has already been used,
+// batches are keyed separately from single creates.
+func (mi *measurementIdempotency) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	key := idempotencyKeyFrom(ctx)
+	if key == "" {
+		return mi.MeasurementDB.CreateBatch(measurements, ctx)
+	}
+
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+
+	record, existing, err := mi.keys.reserve(uc.UserID, "MeasurementBatches", key)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		var results []BatchResult
+		if err := json.Unmarshal([]byte(existing.Results), &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	results, err := mi.MeasurementDB.CreateBatch(measurements, ctx)
+	if err != nil {
+		mi.keys.release(record)
+		return nil, err
+	}
+	if err := mi.keys.completeBatch(record, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+type alarmIdempotency struct {
+	AlarmDB
+	keys *idempotencyGorm
+}
+
+func (ai *alarmIdempotency) Create(alarm *Alarm, ctx context.Context) error {
+	key := idempotencyKeyFrom(ctx)
+	if key == "" {
+		return ai.AlarmDB.Create(alarm, ctx)
+	}
+
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+
+	record, existing, err := ai.keys.reserve(uc.UserID, "Alarms", key)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		original, err := ai.AlarmDB.ByID(existing.ResourceID, ctx)
+		if err != nil {
+			return err
+		}
+		*alarm = *original
+		return nil
+	}
+
+	if err := ai.AlarmDB.Create(alarm, ctx); err != nil {
+		ai.keys.release(record)
+		return err
+	}
+	return ai.keys.complete(record, alarm.ID)
+}
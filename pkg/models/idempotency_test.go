@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubBatches records the batches that reach the database.
+type stubBatches struct {
+	MeasurementDB
+	batches int
+	err     error
+}
+
+func (sb *stubBatches) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	sb.batches++
+	if sb.err != nil {
+		return nil, sb.err
+	}
+	results := make([]BatchResult, len(measurements))
+	for i := range measurements {
+		results[i] = BatchResult{Index: i, ID: uint(100 + i)}
+	}
+	return results, nil
+}
+
+var idempotencyColumns = []string{"id", "user_id", "resource", "key", "resource_id", "results", "created_at"}
+
+// idempotencyResponder answers the reservation insert with a unique index
+// violation when seen is set, and the lookup with seen.
+func idempotencyResponder(seen *IdempotencyKey) func(string, []driver.Value) fakeResult {
+	return func(query string, args []driver.Value) fakeResult {
+		switch {
+		case strings.Contains(query, `INTO "idempotency_keys"`) && seen != nil:
+			return fakeResult{Err: errors.New(`duplicate key value violates unique constraint "idx_idempotency_key"`)}
+		case strings.Contains(query, `INTO "idempotency_keys"`):
+			return fakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(1)}}}
+		case strings.Contains(query, `SELECT * FROM "idempotency_keys"`) && seen != nil:
+			return fakeResult{Columns: idempotencyColumns, Rows: [][]driver.Value{{
+				int64(seen.ID), int64(seen.UserID), seen.Resource, seen.Key, int64(seen.ResourceID), seen.Results, time.Now(),
+			}}}
+		}
+		return fakeResult{}
+	}
+}
+
+func idempotentContext(key string) context.Context {
+	ctx := context.WithValue(context.Background(), userContextKey("User"), &UserClaims{UserID: 7})
+	return WithIdempotencyKey(ctx, key)
+}
+
+func TestIdempotentBatch(t *testing.T) {
+	stored, _ := json.Marshal([]BatchResult{{Index: 0, ID: 41}, {Index: 1, Error: "Type Required"}})
+	batch := []*Measurement{{Type: "temperature"}, {}}
+
+	tests := []struct {
+		name      string
+		seen      *IdempotencyKey
+		createErr error
+		want      []BatchResult
+		wantErr   error
+		batches   int
+		stored    bool
+		released  bool
+	}{
+		{
+			name:    "first use stores the results",
+			want:    []BatchResult{{Index: 0, ID: 100}, {Index: 1, ID: 101}},
+			batches: 1,
+			stored:  true,
+		},
+		{
+			name:    "retry replays the stored results",
+			seen:    &IdempotencyKey{ID: 1, UserID: 7, Resource: "MeasurementBatches", Key: "abc", Results: string(stored)},
+			want:    []BatchResult{{Index: 0, ID: 41}, {Index: 1, Error: "Type Required"}},
+			batches: 0,
+		},
+		{
+			name:    "retry while in flight conflicts",
+			seen:    &IdempotencyKey{ID: 1, UserID: 7, Resource: "MeasurementBatches", Key: "abc"},
+			wantErr: ErrIdempotencyInFlight,
+		},
+		{
+			name:      "failed batch frees the key",
+			createErr: errors.New("connection reset"),
+			wantErr:   errors.New("connection reset"),
+			batches:   1,
+			released:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeGorm(t, idempotencyResponder(tt.seen))
+			inner := &stubBatches{err: tt.createErr}
+			mi := &measurementIdempotency{MeasurementDB: inner, keys: newIdempotencyGorm(db, 0)}
+
+			got, err := mi.CreateBatch(batch, idempotentContext("abc"))
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("results = %+v, want %+v", got, tt.want)
+			}
+			if inner.batches != tt.batches {
+				t.Errorf("%d batches created, want %d", inner.batches, tt.batches)
+			}
+
+			updates := fake.Calls(`UPDATE "idempotency_keys"`, `"results"`)
+			if tt.stored != (len(updates) == 1) {
+				t.Fatalf("results stored %d times, want stored %v", len(updates), tt.stored)
+			}
+			if tt.stored {
+				var saved []BatchResult
+				if err := json.Unmarshal([]byte(updates[0].Args[0].(string)), &saved); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(saved, tt.want) {
+					t.Errorf("stored %+v, want %+v", saved, tt.want)
+				}
+			}
+
+			deletes := fake.Calls(`DELETE FROM "idempotency_keys"`, `"id" = `)
+			if tt.released != (len(deletes) == 1) {
+				t.Errorf("key released %d times, want released %v", len(deletes), tt.released)
+			}
+		})
+	}
+}
+
+func TestIdempotentBatchWithoutKey(t *testing.T) {
+	db, fake := newFakeGorm(t, nil)
+	inner := &stubBatches{}
+	mi := &measurementIdempotency{MeasurementDB: inner, keys: newIdempotencyGorm(db, 0)}
+
+	if _, err := mi.CreateBatch([]*Measurement{{}}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if inner.batches != 1 || len(fake.Queries()) != 0 {
+		t.Errorf("%d batches and queries %q, want one batch and no queries", inner.batches, fake.Queries())
+	}
+}
+
+func TestReserveFreesAbandonedKeys(t *testing.T) {
+	db, fake := newFakeGorm(t, idempotencyResponder(nil))
+	keys := newIdempotencyGorm(db, time.Hour)
+
+	before := time.Now()
+	if _, _, err := keys.reserve(7, "Alarms", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now()
+
+	sweeps := fake.Calls(`DELETE FROM "idempotency_keys"`, "resource_id = 0")
+	if len(sweeps) != 1 {
+		t.Fatalf("queries %q, want one sweep of abandoned keys", fake.Queries())
+	}
+	expired, abandoned := sweeps[0].Args[0].(time.Time), sweeps[0].Args[1].(time.Time)
+	if expired.Before(before.Add(-time.Hour)) || expired.After(after.Add(-time.Hour)) {
+		t.Errorf("expired before %v, want an hour ago", expired)
+	}
+	timeout := IdempotencyReservationTimeout
+	if abandoned.Before(before.Add(-timeout)) || abandoned.After(after.Add(-timeout)) {
+		t.Errorf("abandoned before %v, want %v ago", abandoned, timeout)
+	}
+}
+
+func TestInFlightIsConflict(t *testing.T) {
+	if _, ok := error(ErrIdempotencyInFlight).(ErrorConflict); !ok {
+		t.Errorf("ErrIdempotencyInFlight is %T, want ErrorConflict", ErrIdempotencyInFlight)
+	}
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ImportColumns are the CSV columns an import must have, in any order.
+var ImportColumns = []string{"device", "type", "value", "unit", "timestamp"}
+
+const DefaultImportBatchSize = 500
+
+// ImportRowError is a row that couldn't be imported, Row counts from one
+// for the first data row after the header.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarises an import, on a dry run Imported counts the rows
+// that would have been imported.
+type ImportReport struct {
+	DryRun   bool             `json:"dryRun"`
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// MeasurementImporter loads measurements from historian CSV exports,
+// resolving devices by name.
+type MeasurementImporter struct {
+	ds        DeviceService
+	ms        MeasurementService
+	BatchSize int
+}
+
+func NewMeasurementImporter(ds DeviceService, ms MeasurementService) *MeasurementImporter {
+	return &MeasurementImporter{
+		ds:        ds,
+		ms:        ms,
+		BatchSize: DefaultImportBatchSize,
+	}
+}
+
+// deviceResolver looks devices up by name, remembering each one for the
+// rest of an import.
+type deviceResolver struct {
+	ds  DeviceService
+	ids map[string]uint
+}
+
+func newDeviceResolver(ds DeviceService) *deviceResolver {
+	return &deviceResolver{ds: ds, ids: map[string]uint{}}
+}
+
+func (dr *deviceResolver) resolve(name string, ctx context.Context) (uint, error) {
+	if id, ok := dr.ids[name]; ok {
+		return id, nil
+	}
+	device, err := dr.ds.ByName(name, ctx)
+	if gorm.IsRecordNotFoundError(err) {
+		return 0, ErrorNotFound(fmt.Sprintf("Unknown Device %s", name))
+	}
+	if err != nil {
+		return 0, err
+	}
+	dr.ids[name] = device.ID
+	return device.ID, nil
+}
+
+// Import validates every row of r and inserts the valid ones in batches.
+// Rows are streamed, so only the current batch and the errors are held in
+// memory. A dry run puts each batch through the same validation, but
+// inserts nothing.
+func (mi *MeasurementImporter) Import(r io.Reader, dryRun bool, ctx context.Context) (*ImportReport, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, ErrImportHeader
+	}
+	columns, err := importColumnIndexes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: dryRun, Errors: []ImportRowError{}}
+	devices := newDeviceResolver(mi.ds)
+	var batch []*Measurement
+	var batchRows []int
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		create := mi.ms.CreateBatch
+		if dryRun {
+			create = mi.ms.Validate
+		}
+		results, err := create(batch, ctx)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				report.fail(batchRows[result.Index], result.Error)
+				continue
+			}
+			report.Imported++
+		}
+		batch, batchRows = nil, nil
+		return nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		report.Rows++
+		row := report.Rows
+		if err != nil {
+			report.fail(row, err.Error())
+			continue
+		}
+
+		measurement, err := mi.parseRow(record, columns, devices, ctx)
+		if err != nil {
+			if _, ok := err.(ErrorUnauthorized); ok {
+				return nil, err
+			}
+			report.fail(row, err.Error())
+			continue
+		}
+
+		batch = append(batch, measurement)
+		batchRows = append(batchRows, row)
+		if len(batch) >= mi.BatchSize || len(batch) >= MaxBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *ImportReport) fail(row int, message string) {
+	r.Failed++
+	r.Errors = append(r.Errors, ImportRowError{Row: row, Error: message})
+}
+
+func importColumnIndexes(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range ImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrorBadRequest(fmt.Sprintf("Missing Import Column %s", name))
+		}
+	}
+	return columns, nil
+}
+
+func (mi *MeasurementImporter) parseRow(record []string, columns map[string]int, devices *deviceResolver, ctx context.Context) (*Measurement, error) {
+	field := func(name string) string {
+		i := columns[name]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	name := field("device")
+	if name == "" {
+		return nil, ErrDeviceNameRequired
+	}
+	deviceID, err := devices.resolve(name, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := &Measurement{
+		DeviceID: deviceID,
+		Type:     field("type"),
+		Unit:     field("unit"),
+	}
+	if measurement.Type == "" {
+		return nil, ErrTypeRequired
+	}
+
+	value, err := strconv.ParseFloat(field("value"), 64)
+	if err != nil {
+		return nil, ErrInvalidValue
+	}
+	measurement.Value = value
+
+	observed, err := parseImportTimestamp(field("timestamp"))
+	if err != nil {
+		return nil, err
+	}
+	measurement.ObservedAt = observed
+	return measurement, nil
+}
+
+// Timestamps are RFC3339, or seconds since the Unix epoch.
+func parseImportTimestamp(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+	}
+	return time.Time{}, ErrInvalidTimestamp
+}
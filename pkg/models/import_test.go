@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// stubDevices knows devices by name.
+type stubDevices struct {
+	DeviceService
+	ids map[string]uint
+}
+
+func (sd *stubDevices) ByName(name string, ctx context.Context) (*Device, error) {
+	id, ok := sd.ids[name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &Device{Model: gorm.Model{ID: id}}, nil
+}
+
+func TestImportDryRunValidates(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	csv := strings.Join([]string{
+		"device,type,value,unit,timestamp",
+		"boiler,temperature,21.5,Cel,2026-10-18T10:00:00Z",
+		"boiler,temperature,22,celsius,2026-10-18T10:01:00Z",
+		"boiler,temperature,23,Cel," + future,
+		"boiler,temperature,NaN,Cel,2026-10-18T10:02:00Z",
+		"boiler,,24,Cel,2026-10-18T10:03:00Z",
+		"furnace,temperature,900,Cel,2026-10-18T10:04:00Z",
+	}, "\n")
+
+	for _, dryRun := range []bool{true, false} {
+		db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+			if strings.Contains(query, `INTO "measurements"`) {
+				return fakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(1)}}}
+			}
+			return fakeResult{}
+		})
+		ms := &measurementValidator{MeasurementDB: &measurementGorm{db: db}, futureTolerance: time.Minute}
+		mi := NewMeasurementImporter(&stubDevices{ids: map[string]uint{"boiler": 1}}, ms)
+
+		report, err := mi.Import(strings.NewReader(csv), dryRun, context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Free-form units are kept, the other bad rows fail either way.
+		if report.Rows != 6 || report.Imported != 2 || report.Failed != 4 {
+			t.Errorf("dry run %v: %d rows, %d imported, %d failed, want 6, 2 and 4: %+v", dryRun, report.Rows, report.Imported, report.Failed, report.Errors)
+		}
+		want := map[int]string{3: ErrObservedInFuture.Error(), 4: ErrValueNotFinite.Error(), 5: ErrTypeRequired.Error()}
+		for _, e := range report.Errors {
+			if msg, ok := want[e.Row]; ok && e.Error != msg {
+				t.Errorf("dry run %v: row %d failed with %q, want %q", dryRun, e.Row, e.Error, msg)
+			}
+		}
+
+		inserts := len(fake.Calls(`INTO "measurements"`))
+		if dryRun && inserts != 0 {
+			t.Errorf("dry run inserted %d measurements", inserts)
+		}
+		if !dryRun && inserts != 2 {
+			t.Errorf("inserted %d measurements, want 2", inserts)
+		}
+	}
+}
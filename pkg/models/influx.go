@@ -0,0 +1,171 @@
+package models
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/naspinall/Hive/pkg/lineprotocol"
+)
+
+const (
+	DefaultDeviceTag = "device"
+	DefaultUnitTag   = "unit"
+
+	maxLineLength = 1024 * 1024
+)
+
+// LineProtocolWriter stores InfluxDB line protocol points as measurements.
+//
+// A point's device is named by its DeviceTag, and each field becomes a
+// measurement of type measurement_field. Points without the tag are taken to
+// be named after the device, and each field key is the measurement type.
+type LineProtocolWriter struct {
+	ds        DeviceService
+	ms        MeasurementService
+	DeviceTag string
+	UnitTag   string
+}
+
+func NewLineProtocolWriter(ds DeviceService, ms MeasurementService) *LineProtocolWriter {
+	return &LineProtocolWriter{
+		ds:        ds,
+		ms:        ms,
+		DeviceTag: DefaultDeviceTag,
+		UnitTag:   DefaultUnitTag,
+	}
+}
+
+// Write parses every line of r and stores the points in batches, the report
+// lists the lines that failed by line number.
+func (lw *LineProtocolWriter) Write(r io.Reader, precision time.Duration, ctx context.Context) (*ImportReport, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	report := &ImportReport{Errors: []ImportRowError{}}
+	devices := newDeviceResolver(lw.ds)
+	failed := map[int]bool{}
+	fail := func(line int, message string) {
+		if failed[line] {
+			return
+		}
+		failed[line] = true
+		report.fail(line, message)
+	}
+
+	var batch []*Measurement
+	var batchLines []int
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := lw.ms.CreateBatch(batch, ctx)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				fail(batchLines[result.Index], result.Error)
+			}
+		}
+		batch, batchLines = nil, nil
+		return nil
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if lineprotocol.IsBlank(text) {
+			continue
+		}
+		report.Rows++
+
+		point, err := lineprotocol.Parse(text, precision)
+		if err != nil {
+			fail(line, err.Error())
+			continue
+		}
+
+		measurements, err := lw.measurements(point, devices, ctx)
+		if err != nil {
+			if _, ok := err.(ErrorUnauthorized); ok {
+				return nil, err
+			}
+			fail(line, err.Error())
+			continue
+		}
+
+		for _, measurement := range measurements {
+			batch = append(batch, measurement)
+			batchLines = append(batchLines, line)
+		}
+		if len(batch) >= DefaultImportBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrorBadRequest(err.Error())
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	report.Imported = report.Rows - report.Failed
+	return report, nil
+}
+
+func (lw *LineProtocolWriter) measurements(p *lineprotocol.Point, devices *deviceResolver, ctx context.Context) ([]*Measurement, error) {
+	name, tagged := p.Tags[lw.DeviceTag]
+	if !tagged {
+		name = p.Measurement
+	}
+	deviceID, err := devices.resolve(name, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var measurements []*Measurement
+	for _, field := range p.Fields {
+		value, err := fieldValue(field)
+		if err != nil {
+			return nil, err
+		}
+
+		measurementType := field.Key
+		if tagged {
+			measurementType = p.Measurement + "_" + field.Key
+		}
+
+		measurements = append(measurements, &Measurement{
+			DeviceID:   deviceID,
+			Type:       measurementType,
+			Value:      value,
+			Unit:       p.Tags[lw.UnitTag],
+			ObservedAt: p.Time,
+		})
+	}
+	return measurements, nil
+}
+
+// Booleans are stored as 1 and 0, strings can't be stored as a measurement.
+func fieldValue(field lineprotocol.Field) (float64, error) {
+	switch v := field.Value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, ErrorBadRequest(fmt.Sprintf("String Field %s Not Supported", field.Key))
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Alarm statuses.
+const (
+	AlarmActive       = "ACTIVE"
+	AlarmAcknowledged = "ACKNOWLEDGED"
+	AlarmCleared      = "CLEARED"
+)
+
+// Alarm severities.
+const (
+	SeverityMinor  = "MINOR"
+	SeverityMajor  = "MAJOR"
+	SeveritySevere = "SEVERE"
+)
+
+// alarmTransitions lists the statuses each status may move to.
+var alarmTransitions = map[string][]string{
+	AlarmActive:       {AlarmAcknowledged, AlarmCleared},
+	AlarmAcknowledged: {AlarmCleared},
+	AlarmCleared:      {},
+}
+
+func validSeverity(severity string) bool {
+	switch severity {
+	case SeverityMinor, SeverityMajor, SeveritySevere:
+		return true
+	}
+	return false
+}
+
+// transition moves the alarm to status, recording who moved it and when.
+func (a *Alarm) transition(status string, userID uint, comment string, at time.Time) error {
+	next, ok := alarmTransitions[a.Status]
+	if _, known := alarmTransitions[status]; !ok || !known {
+		return ErrInvalidAlarmStatus
+	}
+
+	allowed := false
+	for _, s := range next {
+		if s == status {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return ErrInvalidTransition
+	}
+
+	a.Status = status
+	switch status {
+	case AlarmAcknowledged:
+		a.AcknowledgedBy = &userID
+		a.AcknowledgedAt = &at
+		a.AcknowledgeComment = comment
+	case AlarmCleared:
+		a.ClearedBy = &userID
+		a.ClearedAt = &at
+		a.ClearComment = comment
+	}
+	return nil
+}
+
+// Transition locks the alarm while it is moved, so two users acting on it at
+// once can't both succeed.
+func (ag *alarmGorm) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+
+	tx := ag.db.Begin()
+	var alarm Alarm
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&alarm).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := alarm.transition(status, uc.UserID, comment, time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Save(&alarm).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &alarm, nil
+}
+
+type alarmValFunc func(*Alarm) error
+
+// alarmValidator checks severities and statuses. Updates can't change the
+// status, that is left to Transition which locks the alarm while it moves.
+type alarmValidator struct {
+	AlarmDB
+}
+
+func (av *alarmValidator) Create(alarm *Alarm, ctx context.Context) error {
+	if err := av.runAlarmValFns(alarm, av.hasType, av.defaultStatus, av.isActive, av.hasSeverity); err != nil {
+		return err
+	}
+	return av.AlarmDB.Create(alarm, ctx)
+}
+
+func (av *alarmValidator) Update(alarm *Alarm, ctx context.Context) error {
+	if err := av.runAlarmValFns(alarm, av.hasType, av.hasSeverity); err != nil {
+		return err
+	}
+
+	previous, err := av.AlarmDB.ByID(alarm.ID, ctx)
+	if err != nil {
+		return err
+	}
+	if alarm.Status != previous.Status {
+		return ErrStatusNeedsTransition
+	}
+	return av.AlarmDB.Update(alarm, ctx)
+}
+
+func (av *alarmValidator) runAlarmValFns(alarm *Alarm, fns ...alarmValFunc) error {
+	for _, fn := range fns {
+		if err := fn(alarm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (av *alarmValidator) hasType(alarm *Alarm) error {
+	if alarm.Type == "" {
+		return ErrAlarmTypeRequired
+	}
+	return nil
+}
+
+func (av *alarmValidator) defaultStatus(alarm *Alarm) error {
+	if alarm.Status == "" {
+		alarm.Status = AlarmActive
+	}
+	return nil
+}
+
+// New alarms are always raised, acknowledging and clearing come later.
+func (av *alarmValidator) isActive(alarm *Alarm) error {
+	if alarm.Status != AlarmActive {
+		return ErrInvalidAlarmStatus
+	}
+	return nil
+}
+
+func (av *alarmValidator) hasSeverity(alarm *Alarm) error {
+	if !validSeverity(alarm.Severity) {
+		return ErrInvalidSeverity
+	}
+	return nil
+}
+
+func (aw *alarmWebhook) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	alarm, err := aw.AlarmDB.Transition(id, status, comment, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if alarm.Suppressed {
+		return alarm, nil
+	}
+
+	err = aw.Subscription.Webhook(alarm.DeviceID, "UPDATE", "ALARM", alarm)
+	// Don't want to error for a bad webhook, will just log.
+	if err != nil {
+		log.Println(err)
+	}
+	return alarm, nil
+}
+
+func (ae *alarmEvents) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	alarm, err := ae.AlarmDB.Transition(id, status, comment, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ae.hub.Publish(alarmEvent("UPDATE", alarm))
+	return alarm, nil
+}
+
+func (aa alarmAuthorization) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 3 {
+		return nil, ErrAlarmsUpdateRequired
+	}
+	return aa.AlarmDB.Transition(id, status, comment, ctx)
+}
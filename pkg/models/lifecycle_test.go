@@ -0,0 +1,77 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+// stubStored holds an alarm as stored and records the updates written.
+type stubStored struct {
+	AlarmDB
+	stored  Alarm
+	updates int
+}
+
+func (ss *stubStored) ByID(id uint, ctx context.Context) (*Alarm, error) {
+	alarm := ss.stored
+	return &alarm, nil
+}
+
+func (ss *stubStored) Update(alarm *Alarm, ctx context.Context) error {
+	ss.updates++
+	return nil
+}
+
+func TestAlarmValidatorUpdate(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(*Alarm)
+		err    error
+	}{
+		{"severity", func(a *Alarm) { a.Severity = "MINOR" }, nil},
+		{"acknowledge", func(a *Alarm) { a.Status = AlarmAcknowledged }, ErrStatusNeedsTransition},
+		{"clear", func(a *Alarm) { a.Status = AlarmCleared }, ErrStatusNeedsTransition},
+		{"bad severity", func(a *Alarm) { a.Severity = "LOUD" }, ErrInvalidSeverity},
+		{"no type", func(a *Alarm) { a.Type = "" }, ErrAlarmTypeRequired},
+	}
+	for _, c := range cases {
+		stored := Alarm{Model: gorm.Model{ID: 4}, Type: "temperature", Status: AlarmActive, Severity: "MAJOR"}
+		db := &stubStored{stored: stored}
+
+		alarm := stored
+		c.change(&alarm)
+		err := (&alarmValidator{AlarmDB: db}).Update(&alarm, context.Background())
+		if err != c.err {
+			t.Errorf("%s: error %v, want %v", c.name, err, c.err)
+		}
+		if wrote := db.updates == 1; wrote != (c.err == nil) {
+			t.Errorf("%s: %d updates written", c.name, db.updates)
+		}
+	}
+}
+
+// An update writing a stale status would undo a Transition that committed
+// between the update's read and its write.
+func TestAlarmUpdateLeavesLifecycle(t *testing.T) {
+	db, fake := newFakeGorm(t, nil)
+	alarm := &Alarm{Model: gorm.Model{ID: 4}, Type: "temperature", Status: AlarmActive, Severity: "MINOR"}
+	if err := (&alarmGorm{db: db}).Update(alarm, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := fake.Calls(`UPDATE "alarms"`)
+	if len(updates) != 1 {
+		t.Fatalf("queries %q, want one alarm update", fake.Queries())
+	}
+	if !strings.Contains(updates[0].Query, `"severity"`) {
+		t.Errorf("update doesn't write the severity: %s", updates[0].Query)
+	}
+	for _, column := range alarmLifecycleColumns {
+		if strings.Contains(updates[0].Query, `"`+column+`"`) {
+			t.Errorf("update writes %s: %s", column, updates[0].Query)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/naspinall/Hive/pkg/cron"
+)
+
+// MaxMaintenanceDuration bounds how long a recurring window may stay open.
+const MaxMaintenanceDuration = 7 * 24 * time.Hour
+
+// MaintenanceWindow suppresses alarms raised while it is open. A one-off
+// window is open from StartsAt until EndsAt. A recurring window opens each
+// time its cron Schedule matches, in Timezone, and stays open for
+// DurationMinutes.
+//
+// Like a rule, a window is scoped to a device, a group of devices, or every
+// device when neither is set, and to a single alarm type when one is given.
+type MaintenanceWindow struct {
+	gorm.Model
+	Name            string     `gorm:"not null" json:"name"`
+	DeviceID        uint       `gorm:"index" json:"deviceId"`
+	Group           string     `gorm:"index" json:"group"`
+	AlarmType       string     `json:"alarmType"`
+	StartsAt        *time.Time `json:"startsAt"`
+	EndsAt          *time.Time `json:"endsAt"`
+	Schedule        string     `json:"schedule"`
+	DurationMinutes uint       `json:"durationMinutes"`
+	Timezone        string     `json:"timezone"`
+
+	// The parsed Schedule and Timezone of a recurring window.
+	schedule *cron.Schedule
+	location *time.Location
+}
+
+// compile parses the schedule and loads the timezone of a recurring window,
+// unless that has already been done.
+func (w *MaintenanceWindow) compile() error {
+	if w.Schedule == "" || w.schedule != nil {
+		return nil
+	}
+	schedule, err := cron.Parse(w.Schedule)
+	if err != nil {
+		return err
+	}
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return err
+	}
+	w.schedule, w.location = schedule, location
+	return nil
+}
+
+// open reports whether the window is open at t.
+func (w *MaintenanceWindow) open(t time.Time) (bool, error) {
+	if w.Schedule == "" {
+		return w.StartsAt != nil && w.EndsAt != nil && !t.Before(*w.StartsAt) && t.Before(*w.EndsAt), nil
+	}
+	if err := w.compile(); err != nil {
+		return false, err
+	}
+
+	// The window is open if it last opened less than its duration ago.
+	duration := time.Duration(w.DurationMinutes) * time.Minute
+	opened, ok := w.schedule.Prev(t.In(w.location), duration)
+	return ok && t.Before(opened.Add(duration)), nil
+}
+
+// windowCache keeps the compiled schedules of recurring windows, so they are
+// parsed once rather than for every alarm. Windows are keyed by their
+// schedule and timezone, which bounds the cache by the distinct schedules in
+// use and means an edited window never finds a stale entry.
+type windowCache struct {
+	mu       sync.Mutex
+	compiled map[string]*MaintenanceWindow
+}
+
+func (wc *windowCache) compile(w *MaintenanceWindow) error {
+	if w.Schedule == "" {
+		return nil
+	}
+	key := w.Schedule + "\x00" + w.Timezone
+
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if compiled, ok := wc.compiled[key]; ok {
+		w.schedule, w.location = compiled.schedule, compiled.location
+		return nil
+	}
+	if err := w.compile(); err != nil {
+		return err
+	}
+	if wc.compiled == nil {
+		wc.compiled = map[string]*MaintenanceWindow{}
+	}
+	wc.compiled[key] = &MaintenanceWindow{schedule: w.schedule, location: w.location}
+	return nil
+}
+
+type maintenanceGorm struct {
+	db *gorm.DB
+}
+
+type maintenanceValidator struct {
+	MaintenanceDB
+}
+
+type maintenanceAuditLogger struct {
+	MaintenanceDB
+}
+
+type maintenanceAuthorization struct {
+	MaintenanceDB
+}
+
+type windowValFunc func(*MaintenanceWindow) error
+
+type MaintenanceService interface {
+	MaintenanceDB
+}
+
+type MaintenanceDB interface {
+	ByID(id uint, ctx context.Context) (*MaintenanceWindow, error)
+	Many(ctx context.Context) ([]*MaintenanceWindow, error)
+	Create(window *MaintenanceWindow, ctx context.Context) error
+	Update(window *MaintenanceWindow, ctx context.Context) error
+	Delete(id uint, ctx context.Context) error
+}
+
+func NewMaintenanceService(db *gorm.DB) MaintenanceService {
+	return &maintenanceAuthorization{
+		&maintenanceAuditLogger{
+			&maintenanceValidator{
+				&maintenanceGorm{
+					db: db,
+				},
+			},
+		},
+	}
+}
+
+func (mg *maintenanceGorm) ByID(id uint, ctx context.Context) (*MaintenanceWindow, error) {
+	var window MaintenanceWindow
+	if err := mg.db.Where("id = ?", id).First(&window).Error; err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (mg *maintenanceGorm) Many(ctx context.Context) ([]*MaintenanceWindow, error) {
+	windows := []*MaintenanceWindow{}
+	if err := mg.db.Order("id").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (mg *maintenanceGorm) Create(window *MaintenanceWindow, ctx context.Context) error {
+	return mg.db.Create(window).Error
+}
+
+func (mg *maintenanceGorm) Update(window *MaintenanceWindow, ctx context.Context) error {
+	return mg.db.Save(window).Error
+}
+
+func (mg *maintenanceGorm) Delete(id uint, ctx context.Context) error {
+	window := MaintenanceWindow{Model: gorm.Model{ID: id}}
+	return mg.db.Delete(&window).Error
+}
+
+func (mv *maintenanceValidator) Create(window *MaintenanceWindow, ctx context.Context) error {
+	if err := mv.runWindowValFns(window, mv.hasName, mv.oneScope, mv.defaultTimezone, mv.validTimes); err != nil {
+		return err
+	}
+	return mv.MaintenanceDB.Create(window, ctx)
+}
+
+func (mv *maintenanceValidator) Update(window *MaintenanceWindow, ctx context.Context) error {
+	if err := mv.runWindowValFns(window, mv.hasName, mv.oneScope, mv.defaultTimezone, mv.validTimes); err != nil {
+		return err
+	}
+	return mv.MaintenanceDB.Update(window, ctx)
+}
+
+func (mv *maintenanceValidator) runWindowValFns(window *MaintenanceWindow, fns ...windowValFunc) error {
+	for _, fn := range fns {
+		if err := fn(window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mv *maintenanceValidator) hasName(window *MaintenanceWindow) error {
+	if window.Name == "" {
+		return ErrWindowNameRequired
+	}
+	return nil
+}
+
+func (mv *maintenanceValidator) oneScope(window *MaintenanceWindow) error {
+	if window.DeviceID != 0 && window.Group != "" {
+		return ErrWindowScope
+	}
+	return nil
+}
+
+func (mv *maintenanceValidator) defaultTimezone(window *MaintenanceWindow) error {
+	if window.Timezone == "" {
+		window.Timezone = "UTC"
+	}
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return ErrInvalidTimezone
+	}
+	window.location = location
+	return nil
+}
+
+// A window either has a start and end, or a schedule and duration.
+func (mv *maintenanceValidator) validTimes(window *MaintenanceWindow) error {
+	if window.Schedule == "" {
+		if window.StartsAt == nil || window.EndsAt == nil || !window.StartsAt.Before(*window.EndsAt) {
+			return ErrInvalidWindow
+		}
+		window.DurationMinutes = 0
+		return nil
+	}
+
+	if window.StartsAt != nil || window.EndsAt != nil {
+		return ErrInvalidWindow
+	}
+	schedule, err := cron.Parse(window.Schedule)
+	if err != nil {
+		return ErrorBadRequest("Invalid Schedule: " + err.Error())
+	}
+	window.schedule = schedule
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+	if duration <= 0 || duration > MaxMaintenanceDuration {
+		return ErrInvalidWindowDuration
+	}
+	return nil
+}
+
+func (ma *maintenanceAuditLogger) ByID(id uint, ctx context.Context) (*MaintenanceWindow, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "MaintenanceWindows")
+	return ma.MaintenanceDB.ByID(id, ctx)
+}
+
+func (ma *maintenanceAuditLogger) Many(ctx context.Context) ([]*MaintenanceWindow, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "MaintenanceWindows")
+	return ma.MaintenanceDB.Many(ctx)
+}
+
+func (ma *maintenanceAuditLogger) Create(window *MaintenanceWindow, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "MaintenanceWindows")
+	return ma.MaintenanceDB.Create(window, ctx)
+}
+
+func (ma *maintenanceAuditLogger) Update(window *MaintenanceWindow, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogUpdate(uc.UserID, "MaintenanceWindows")
+	return ma.MaintenanceDB.Update(window, ctx)
+}
+
+func (ma *maintenanceAuditLogger) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "MaintenanceWindows")
+	return ma.MaintenanceDB.Delete(id, ctx)
+}
+
+// Maintenance windows silence alarms, so they share the alarms role.
+func (ma *maintenanceAuthorization) ByID(id uint, ctx context.Context) (*MaintenanceWindow, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ma.MaintenanceDB.ByID(id, ctx)
+}
+func (ma *maintenanceAuthorization) Many(ctx context.Context) ([]*MaintenanceWindow, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ma.MaintenanceDB.Many(ctx)
+}
+func (ma *maintenanceAuthorization) Create(window *MaintenanceWindow, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 2 {
+		return ErrAlarmsWriteRequired
+	}
+	return ma.MaintenanceDB.Create(window, ctx)
+}
+func (ma *maintenanceAuthorization) Update(window *MaintenanceWindow, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 3 {
+		return ErrAlarmsUpdateRequired
+	}
+	return ma.MaintenanceDB.Update(window, ctx)
+}
+func (ma *maintenanceAuthorization) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 4 {
+		return ErrAlarmsDeleteRequired
+	}
+	return ma.MaintenanceDB.Delete(id, ctx)
+}
+
+// alarmMaintenance flags alarms raised inside an open maintenance window as
+// suppressed. Suppressed alarms are stored as usual, but skip webhooks,
+// notifications and escalation.
+type alarmMaintenance struct {
+	AlarmDB
+	db      *gorm.DB
+	windows windowCache
+}
+
+func (am *alarmMaintenance) Create(alarm *Alarm, ctx context.Context) error {
+	suppressed, err := am.inMaintenance(alarm, time.Now())
+	if err != nil {
+		return err
+	}
+	alarm.Suppressed = suppressed
+	return am.AlarmDB.Create(alarm, ctx)
+}
+
+// One-off windows that aren't open are ruled out by the query, recurring
+// windows are checked against their schedule.
+func (am *alarmMaintenance) inMaintenance(alarm *Alarm, now time.Time) (bool, error) {
+	var windows []*MaintenanceWindow
+	err := am.db.Where("alarm_type = '' OR alarm_type = ?", alarm.Type).
+		Where(`device_id = ? OR (device_id = 0 AND ("group" = '' OR "group" IN (SELECT "group" FROM devices WHERE id = ?)))`,
+			alarm.DeviceID, alarm.DeviceID).
+		Where("schedule <> '' OR (starts_at <= ? AND ends_at > ?)", now, now).
+		Find(&windows).Error
+	if err != nil {
+		return false, err
+	}
+
+	for _, window := range windows {
+		if err := am.windows.compile(window); err != nil {
+			return false, err
+		}
+		open, err := window.open(now)
+		if err != nil {
+			return false, err
+		}
+		if open {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowOpen(t *testing.T) {
+	start := time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	oneOff := &MaintenanceWindow{StartsAt: &start, EndsAt: &end}
+	nightly := &MaintenanceWindow{Schedule: "0 2 * * *", DurationMinutes: 60, Timezone: "UTC"}
+	// 02:00 in Sydney is 15:00 UTC the day before, during daylight saving.
+	sydney := &MaintenanceWindow{Schedule: "0 2 * * *", DurationMinutes: 60, Timezone: "Australia/Sydney"}
+
+	cases := []struct {
+		name   string
+		window *MaintenanceWindow
+		at     time.Time
+		want   bool
+	}{
+		{"one-off before", oneOff, start.Add(-time.Nanosecond), false},
+		{"one-off start", oneOff, start, true},
+		{"one-off last instant", oneOff, end.Add(-time.Nanosecond), true},
+		{"one-off end", oneOff, end, false},
+		{"one-off unset", &MaintenanceWindow{}, start, false},
+
+		{"recurring before", nightly, start.Add(-time.Nanosecond), false},
+		{"recurring opens", nightly, start, true},
+		{"recurring last minute", nightly, end.Add(-time.Minute), true},
+		{"recurring last instant", nightly, end.Add(-time.Nanosecond), true},
+		{"recurring closes", nightly, end, false},
+		{"recurring next day", nightly, start.Add(24 * time.Hour), true},
+
+		{"timezone opens", sydney, time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC), true},
+		{"timezone closes", sydney, time.Date(2026, 10, 17, 16, 0, 0, 0, time.UTC), false},
+		{"timezone utc hours", sydney, start, false},
+	}
+	for _, c := range cases {
+		got, err := c.window.open(c.at)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: open at %v = %v, want %v", c.name, c.at, got, c.want)
+		}
+	}
+}
+
+func TestInMaintenanceParsesSchedulesOnce(t *testing.T) {
+	db, _ := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, `FROM "maintenance_windows"`) {
+			return fakeResult{
+				Columns: []string{"id", "schedule", "duration_minutes", "timezone"},
+				Rows: [][]driver.Value{
+					{int64(1), "0 2 * * *", int64(60), "Australia/Sydney"},
+					{int64(2), "0 2 * * *", int64(60), "Australia/Sydney"},
+					{int64(3), "0 2 * * *", int64(60), "UTC"},
+				},
+			}
+		}
+		return fakeResult{}
+	})
+	am := &alarmMaintenance{db: db}
+	alarm := &Alarm{DeviceID: 1, Type: "temperature"}
+
+	for _, at := range []time.Time{
+		time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC),
+		time.Date(2026, 10, 18, 3, 30, 0, 0, time.UTC),
+	} {
+		suppressed, err := am.inMaintenance(alarm, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := at.Hour() == 2; suppressed != want {
+			t.Errorf("suppressed at %v = %v, want %v", at, suppressed, want)
+		}
+	}
+	if n := len(am.windows.compiled); n != 2 {
+		t.Errorf("compiled %d schedules, want one per schedule and timezone", n)
+	}
+
+	first, second := &MaintenanceWindow{Schedule: "0 2 * * *", Timezone: "UTC"}, &MaintenanceWindow{Schedule: "0 2 * * *", Timezone: "UTC"}
+	if err := am.windows.compile(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.windows.compile(second); err != nil {
+		t.Fatal(err)
+	}
+	if first.schedule != second.schedule || first.location != second.location {
+		t.Error("parsed the same schedule twice")
+	}
+}
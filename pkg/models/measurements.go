@@ -3,22 +3,55 @@ package models
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
 
+// Measurement is a single reading from a device. ObservedAt is when the
+// device took the reading, CreatedAt is when Hive received it.
 type Measurement struct {
 	gorm.Model
-	Type     string  `gorm:"not null"`
-	Value    float64 `gorm:"not null"`
-	Unit     string  `gorm:"not null"`
-	DeviceID uint
-	Device   Device `json:"-"`
+	Type       string    `gorm:"not null"`
+	Value      float64   `gorm:"not null"`
+	Unit       string    `gorm:"not null"`
+	ObservedAt time.Time `gorm:"not null;index"`
+	DeviceID   uint
+	Device     Device `json:"-"`
 }
 
+// MeasurementQuery narrows a device's measurements to a time range and type,
+// and carries the cursor used to page through the results.
+type MeasurementQuery struct {
+	From   *time.Time
+	To     *time.Time
+	Type   string
+	Order  string
+	Limit  int
+	Cursor string
+}
+
+// MeasurementPage is a single page of measurements, Next is empty when there
+// are no more results.
+type MeasurementPage struct {
+	Measurements []Measurement `json:"measurements"`
+	Next         string        `json:"next,omitempty"`
+}
+
+const (
+	OrderAscending  = "asc"
+	OrderDescending = "desc"
+
+	DefaultMeasurementLimit = 100
+	MaxMeasurementLimit     = 1000
+)
+
 type measurementGorm struct {
 	db *gorm.DB
 }
@@ -31,25 +64,69 @@ type measurementAuthorization struct {
 	MeasurementDB
 }
 
+type measurementValidator struct {
+	MeasurementDB
+	futureTolerance time.Duration
+}
+
+type measurementValFunc func(*Measurement) error
+
+// BatchResult reports the outcome of a single item of a batch, matched to the
+// request by its index.
+type BatchResult struct {
+	Index int    `json:"index"`
+	ID    uint   `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+const MaxBatchSize = 1000
+
 type MeasurementService interface {
 	MeasurementDB
 }
 
 type MeasurementDB interface {
 	ByID(id uint, ctx context.Context) (*Measurement, error)
-	ByDevice(id uint, ctx context.Context) ([]Measurement, error)
+	ByDevice(id uint, query *MeasurementQuery, ctx context.Context) (*MeasurementPage, error)
+	Aggregate(id uint, query *AggregateQuery, ctx context.Context) ([]AggregateBucket, error)
+	State(ids []uint, ctx context.Context) ([]MeasurementState, error)
+	Export(query *ExportQuery, ctx context.Context, fn func(*Measurement) error) error
 	Create(measurement *Measurement, ctx context.Context) error
+	CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error)
+	Validate(measurements []*Measurement, ctx context.Context) ([]BatchResult, error)
 	Update(measurement *Measurement, ctx context.Context) error
 	Delete(id uint, ctx context.Context) error
 }
 
-func NewMeasurementService(db *gorm.DB, Subscription SubscriptionService) MeasurementService {
+// DefaultFutureTolerance is how far ahead of the server clock a reading's
+// observation time may be before it is rejected.
+const DefaultFutureTolerance = 5 * time.Minute
+
+func NewMeasurementService(db *gorm.DB, Subscription SubscriptionService, hub *EventHub, engine *RuleEngine, heartbeat *HeartbeatMonitor, futureTolerance, idempotencyWindow time.Duration) MeasurementService {
 	return &measurementAuthorization{
-		&measurementWebhook{
-			Subscription: Subscription,
-			MeasurementDB: &measurementAuditLogger{
-				&measurementGorm{
-					db: db,
+		&measurementIdempotency{
+			keys: newIdempotencyGorm(db, idempotencyWindow),
+			MeasurementDB: &measurementRules{
+				engine: engine,
+				MeasurementDB: &measurementHeartbeat{
+					heartbeat: heartbeat,
+					MeasurementDB: &measurementEvents{
+						hub: hub,
+						MeasurementDB: &measurementWebhook{
+							Subscription: Subscription,
+							MeasurementDB: &measurementAuditLogger{
+								&measurementValidator{
+									MeasurementDB: &measurementState{
+										MeasurementDB: &measurementGorm{
+											db: db,
+										},
+										db: db,
+									},
+									futureTolerance: futureTolerance,
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -61,14 +138,90 @@ type measurementWebhook struct {
 	MeasurementDB
 }
 
-func (mg *measurementGorm) ByDevice(id uint, ctx context.Context) ([]Measurement, error) {
+func (mg *measurementGorm) ByDevice(id uint, query *MeasurementQuery, ctx context.Context) (*MeasurementPage, error) {
+	if err := query.normalise(); err != nil {
+		return nil, err
+	}
+
+	db := mg.db.Where("device_id = ?", id)
+	if query.From != nil {
+		db = db.Where("observed_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("observed_at < ?", *query.To)
+	}
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+
+	// Keyset pagination, continuing after the last row of the previous page.
+	if query.Cursor != "" {
+		at, lastID, err := decodeMeasurementCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if query.Order == OrderDescending {
+			db = db.Where("(observed_at, id) < (?, ?)", at, lastID)
+		} else {
+			db = db.Where("(observed_at, id) > (?, ?)", at, lastID)
+		}
+	}
 
-	device := Device{Model: gorm.Model{ID: id}}
+	// Fetching one extra row to know if there is another page.
 	measurements := []Measurement{}
-	if err := mg.db.Model(&device).Related(&measurements).Error; err != nil {
+	order := fmt.Sprintf("observed_at %[1]s, id %[1]s", query.Order)
+	if err := db.Order(order).Limit(query.Limit + 1).Find(&measurements).Error; err != nil {
 		return nil, err
 	}
-	return measurements, nil
+
+	page := &MeasurementPage{Measurements: measurements}
+	if len(measurements) > query.Limit {
+		page.Measurements = measurements[:query.Limit]
+		last := page.Measurements[query.Limit-1]
+		page.Next = encodeMeasurementCursor(last.ObservedAt, last.ID)
+	}
+	return page, nil
+}
+
+func (q *MeasurementQuery) normalise() error {
+	switch q.Order {
+	case "":
+		q.Order = OrderAscending
+	case OrderAscending, OrderDescending:
+	default:
+		return ErrInvalidOrder
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultMeasurementLimit
+	}
+	if q.Limit > MaxMeasurementLimit {
+		q.Limit = MaxMeasurementLimit
+	}
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+func encodeMeasurementCursor(at time.Time, id uint) string {
+	raw := fmt.Sprintf("%d:%d", at.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeMeasurementCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	var nanos int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.Unix(0, nanos).UTC(), id, nil
 }
 
 func (mg *measurementGorm) ByID(id uint, ctx context.Context) (*Measurement, error) {
@@ -89,9 +242,43 @@ func (mg *measurementGorm) Create(measurement *Measurement, ctx context.Context)
 	return nil
 }
 
+// Inserts every measurement in a single transaction, if any insert fails the
+// whole batch is rolled back.
+func (mg *measurementGorm) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	tx := mg.db.BeginTx(ctx, nil)
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(measurements))
+	for i, measurement := range measurements {
+		if err := tx.Create(measurement).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		results[i] = BatchResult{Index: i, ID: measurement.ID}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (mg *measurementGorm) Update(measurement *Measurement, ctx context.Context) error {
 	return mg.db.Save(measurement).Error
 }
+
+// Validate reports how CreateBatch would fare. Everything is checked above
+// the database, so each measurement that gets here would be created.
+func (mg *measurementGorm) Validate(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	results := make([]BatchResult, len(measurements))
+	for i := range results {
+		results[i].Index = i
+	}
+	return results, nil
+}
+
 func (mg *measurementGorm) Delete(id uint, ctx context.Context) error {
 	measurement := Measurement{Model: gorm.Model{ID: id}}
 	return mg.db.Delete(measurement).Error
@@ -137,6 +324,33 @@ func (mw *measurementWebhook) Create(alarm *Measurement, ctx context.Context) er
 	return nil
 }
 
+// Webhooks for a batch are sent in the background so large uploads aren't held
+// up by slow subscribers.
+func (mw *measurementWebhook) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	results, err := mw.MeasurementDB.CreateBatch(measurements, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []*Measurement
+	for _, result := range results {
+		if result.Error == "" {
+			created = append(created, measurements[result.Index])
+		}
+	}
+
+	go func() {
+		for _, measurement := range created {
+			err := mw.Subscription.Webhook(measurement.DeviceID, "CREATE", "MEASUREMENT", measurement)
+			// Don't want to error for a bad webhook, will just log.
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}()
+	return results, nil
+}
+
 func (mw *measurementWebhook) Update(measurement *Measurement, ctx context.Context) error {
 	err := mw.MeasurementDB.Update(measurement, ctx)
 	if err != nil {
@@ -189,6 +403,15 @@ func (ma *measurementAuditLogger) Create(measurement *Measurement, ctx context.C
 	return ma.MeasurementDB.Create(measurement, ctx)
 }
 
+func (ma *measurementAuditLogger) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogCreate(uc.UserID, "Measurements")
+	return ma.MeasurementDB.CreateBatch(measurements, ctx)
+}
+
 func (ma *measurementAuditLogger) Update(measurement *Measurement, ctx context.Context) error {
 	uc, err := ExtractUserClaims(ctx)
 	if err != nil {
@@ -207,13 +430,13 @@ func (ma *measurementAuditLogger) Delete(id uint, ctx context.Context) error {
 	return ma.MeasurementDB.Delete(id, ctx)
 }
 
-func (ma *measurementAuditLogger) ByDevice(id uint, ctx context.Context) ([]Measurement, error) {
+func (ma *measurementAuditLogger) ByDevice(id uint, query *MeasurementQuery, ctx context.Context) (*MeasurementPage, error) {
 	uc, err := ExtractUserClaims(ctx)
 	if err != nil {
 		return nil, ErrNoClaims
 	}
-	LogDelete(uc.UserID, "Measurements")
-	return ma.MeasurementDB.ByDevice(id, ctx)
+	LogGet(uc.UserID, "Measurements")
+	return ma.MeasurementDB.ByDevice(id, query, ctx)
 }
 
 func (ma *measurementAuthorization) ByID(id uint, ctx context.Context) (*Measurement, error) {
@@ -224,13 +447,13 @@ func (ma *measurementAuthorization) ByID(id uint, ctx context.Context) (*Measure
 	}
 	return ma.MeasurementDB.ByID(id, ctx)
 }
-func (ma *measurementAuthorization) ByDevice(id uint, ctx context.Context) ([]Measurement, error) {
+func (ma *measurementAuthorization) ByDevice(id uint, query *MeasurementQuery, ctx context.Context) (*MeasurementPage, error) {
 	uc, err := ExtractUserClaims(ctx)
 	ar := uc.Role.Measurements
 	if err != nil || ar < 1 {
 		return nil, ErrMeasurementReadRequired
 	}
-	return ma.MeasurementDB.ByDevice(id, ctx)
+	return ma.MeasurementDB.ByDevice(id, query, ctx)
 }
 func (ma *measurementAuthorization) Create(measurement *Measurement, ctx context.Context) error {
 	uc, err := ExtractUserClaims(ctx)
@@ -240,6 +463,22 @@ func (ma *measurementAuthorization) Create(measurement *Measurement, ctx context
 	}
 	return ma.MeasurementDB.Create(measurement, ctx)
 }
+func (ma *measurementAuthorization) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 2 {
+		return nil, ErrMeasurementWriteRequired
+	}
+	return ma.MeasurementDB.CreateBatch(measurements, ctx)
+}
+func (ma *measurementAuthorization) Validate(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 2 {
+		return nil, ErrMeasurementWriteRequired
+	}
+	return ma.MeasurementDB.Validate(measurements, ctx)
+}
 func (ma *measurementAuthorization) Update(measurement *Measurement, ctx context.Context) error {
 	uc, err := ExtractUserClaims(ctx)
 	ar := uc.Role.Measurements
@@ -256,3 +495,137 @@ func (ma *measurementAuthorization) Delete(id uint, ctx context.Context) error {
 	}
 	return ma.MeasurementDB.Delete(id, ctx)
 }
+
+func (mv *measurementValidator) Create(measurement *Measurement, ctx context.Context) error {
+	if err := mv.validate(measurement); err != nil {
+		return err
+	}
+	return mv.MeasurementDB.Create(measurement, ctx)
+}
+
+// Only valid measurements are passed on to be inserted, invalid ones are
+// reported in the results alongside them.
+func (mv *measurementValidator) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	if len(measurements) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchResult, len(measurements))
+	var valid []*Measurement
+	var indexes []int
+	for i, measurement := range measurements {
+		results[i].Index = i
+		if err := mv.validate(measurement); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, measurement)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	created, err := mv.MeasurementDB.CreateBatch(valid, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range created {
+		i := indexes[result.Index]
+		results[i].ID = result.ID
+		results[i].Error = result.Error
+	}
+	return results, nil
+}
+
+// Validate checks a batch as CreateBatch does, passing the valid
+// measurements on so the layers below can check them too.
+func (mv *measurementValidator) Validate(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	if len(measurements) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchResult, len(measurements))
+	var valid []*Measurement
+	var indexes []int
+	for i, measurement := range measurements {
+		results[i].Index = i
+		if err := mv.validate(measurement); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, measurement)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	checked, err := mv.MeasurementDB.Validate(valid, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range checked {
+		results[indexes[result.Index]].Error = result.Error
+	}
+	return results, nil
+}
+
+func (mv *measurementValidator) Update(measurement *Measurement, ctx context.Context) error {
+	if err := mv.validate(measurement); err != nil {
+		return err
+	}
+	return mv.MeasurementDB.Update(measurement, ctx)
+}
+
+func (mv *measurementValidator) validate(m *Measurement) error {
+	return mv.runMeasurementValFns(m, mv.hasDevice, mv.hasType, mv.finiteValue, mv.defaultObservedAt, mv.notInFuture)
+}
+
+func (mv *measurementValidator) runMeasurementValFns(m *Measurement, fns ...measurementValFunc) error {
+	for _, fn := range fns {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mv *measurementValidator) hasDevice(m *Measurement) error {
+	if m.DeviceID == 0 {
+		return ErrDeviceIDRequired
+	}
+	return nil
+}
+
+func (mv *measurementValidator) hasType(m *Measurement) error {
+	if m.Type == "" {
+		return ErrTypeRequired
+	}
+	return nil
+}
+
+// NaN and infinities can't be stored in JSON, or meaningfully aggregated.
+func (mv *measurementValidator) finiteValue(m *Measurement) error {
+	if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+		return ErrValueNotFinite
+	}
+	return nil
+}
+
+// Readings without a device timestamp were observed when they were received.
+func (mv *measurementValidator) defaultObservedAt(m *Measurement) error {
+	if m.ObservedAt.IsZero() {
+		m.ObservedAt = time.Now()
+	}
+	return nil
+}
+
+func (mv *measurementValidator) notInFuture(m *Measurement) error {
+	if m.ObservedAt.After(time.Now().Add(mv.futureTolerance)) {
+		return ErrObservedInFuture
+	}
+	return nil
+}
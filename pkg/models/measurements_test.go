@@ -0,0 +1,28 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasurementFiniteValue(t *testing.T) {
+	mv := &measurementValidator{}
+	cases := []struct {
+		value float64
+		err   error
+	}{
+		{0, nil},
+		{-40.5, nil},
+		{math.MaxFloat64, nil},
+		{-math.MaxFloat64, nil},
+		{math.SmallestNonzeroFloat64, nil},
+		{math.NaN(), ErrValueNotFinite},
+		{math.Inf(1), ErrValueNotFinite},
+		{math.Inf(-1), ErrValueNotFinite},
+	}
+	for _, c := range cases {
+		if err := mv.finiteValue(&Measurement{Value: c.value}); err != c.err {
+			t.Errorf("finiteValue(%v) = %v, want %v", c.value, err, c.err)
+		}
+	}
+}
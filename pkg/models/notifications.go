@@ -0,0 +1,570 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/mail"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/naspinall/Hive/pkg/notify"
+)
+
+// Notification channel kinds.
+const (
+	ChannelEmail = "email"
+	ChannelChat  = "chat"
+	ChannelHTTP  = "http"
+)
+
+// Notification actions, the alarm either was raised or has cleared.
+const (
+	NotifyRaised  = "RAISED"
+	NotifyCleared = "CLEARED"
+)
+
+const (
+	defaultSubjectTemplate = `[{{.Alarm.Severity}}] {{.Alarm.Type}} {{.Action}} on {{.Device.Name}}`
+	defaultBodyTemplate    = `Alarm {{.Alarm.ID}} {{.Alarm.Type}} was {{.Action}} on {{.Device.Name}} with severity {{.Alarm.Severity}}.`
+)
+
+// NotificationChannel is somewhere a human readable notification is sent.
+// Target is a comma separated list of addresses for email, and a URL for chat
+// and http channels.
+//
+// Subject and Body are Go templates over a NotificationData, empty templates
+// use a short default. ContentType is only sent by http channels.
+type NotificationChannel struct {
+	gorm.Model
+	Name        string `gorm:"not null" json:"name"`
+	Kind        string `gorm:"not null" json:"kind"`
+	Target      string `gorm:"not null" json:"target"`
+	Subject     string `json:"subject"`
+	Body        string `json:"body"`
+	ContentType string `json:"contentType"`
+}
+
+// NotificationRoute sends alarms to a channel. Empty fields match every
+// alarm, so a route with only a channel receives everything.
+type NotificationRoute struct {
+	gorm.Model
+	ChannelID uint   `gorm:"not null;index" json:"channelId"`
+	Severity  string `json:"severity"`
+	AlarmType string `json:"alarmType"`
+	DeviceID  uint   `json:"deviceId"`
+}
+
+// NotificationData is what channel templates are rendered over. Level counts
+// the escalation steps taken, and is zero for other notifications.
+type NotificationData struct {
+	Action string
+	Alarm  *Alarm
+	Device *Device
+	Level  int
+}
+
+type notificationGorm struct {
+	db *gorm.DB
+}
+
+type notificationValidator struct {
+	NotificationDB
+}
+
+type notificationAuditLogger struct {
+	NotificationDB
+}
+
+type notificationAuthorization struct {
+	NotificationDB
+}
+
+type channelValFunc func(*NotificationChannel) error
+
+type NotificationService interface {
+	NotificationDB
+}
+
+type NotificationDB interface {
+	ChannelByID(id uint, ctx context.Context) (*NotificationChannel, error)
+	Channels(ctx context.Context) ([]*NotificationChannel, error)
+	CreateChannel(channel *NotificationChannel, ctx context.Context) error
+	UpdateChannel(channel *NotificationChannel, ctx context.Context) error
+	DeleteChannel(id uint, ctx context.Context) error
+
+	Routes(ctx context.Context) ([]*NotificationRoute, error)
+	CreateRoute(route *NotificationRoute, ctx context.Context) error
+	DeleteRoute(id uint, ctx context.Context) error
+}
+
+func NewNotificationService(db *gorm.DB) NotificationService {
+	return &notificationAuthorization{
+		&notificationAuditLogger{
+			&notificationValidator{
+				&notificationGorm{
+					db: db,
+				},
+			},
+		},
+	}
+}
+
+func (ng *notificationGorm) ChannelByID(id uint, ctx context.Context) (*NotificationChannel, error) {
+	var channel NotificationChannel
+	if err := ng.db.Where("id = ?", id).First(&channel).Error; err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (ng *notificationGorm) Channels(ctx context.Context) ([]*NotificationChannel, error) {
+	channels := []*NotificationChannel{}
+	if err := ng.db.Order("id").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (ng *notificationGorm) CreateChannel(channel *NotificationChannel, ctx context.Context) error {
+	return ng.db.Create(channel).Error
+}
+
+func (ng *notificationGorm) UpdateChannel(channel *NotificationChannel, ctx context.Context) error {
+	return ng.db.Save(channel).Error
+}
+
+// A channel's routes go with it.
+func (ng *notificationGorm) DeleteChannel(id uint, ctx context.Context) error {
+	channel := NotificationChannel{Model: gorm.Model{ID: id}}
+	if err := ng.db.Delete(&channel).Error; err != nil {
+		return err
+	}
+	return ng.db.Where("channel_id = ?", id).Delete(&NotificationRoute{}).Error
+}
+
+func (ng *notificationGorm) Routes(ctx context.Context) ([]*NotificationRoute, error) {
+	routes := []*NotificationRoute{}
+	if err := ng.db.Order("id").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (ng *notificationGorm) CreateRoute(route *NotificationRoute, ctx context.Context) error {
+	return ng.db.Create(route).Error
+}
+
+func (ng *notificationGorm) DeleteRoute(id uint, ctx context.Context) error {
+	route := NotificationRoute{Model: gorm.Model{ID: id}}
+	return ng.db.Delete(&route).Error
+}
+
+func (nv *notificationValidator) CreateChannel(channel *NotificationChannel, ctx context.Context) error {
+	if err := nv.runChannelValFns(channel, nv.hasName, nv.validTarget, nv.validTemplates); err != nil {
+		return err
+	}
+	return nv.NotificationDB.CreateChannel(channel, ctx)
+}
+
+func (nv *notificationValidator) UpdateChannel(channel *NotificationChannel, ctx context.Context) error {
+	if err := nv.runChannelValFns(channel, nv.hasName, nv.validTarget, nv.validTemplates); err != nil {
+		return err
+	}
+	return nv.NotificationDB.UpdateChannel(channel, ctx)
+}
+
+// A route needs a channel to send to, and a severity Hive knows about.
+func (nv *notificationValidator) CreateRoute(route *NotificationRoute, ctx context.Context) error {
+	if route.ChannelID == 0 {
+		return ErrChannelRequired
+	}
+	if _, err := nv.NotificationDB.ChannelByID(route.ChannelID, ctx); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return ErrChannelRequired
+		}
+		return err
+	}
+	if route.Severity != "" && !validSeverity(route.Severity) {
+		return ErrInvalidSeverity
+	}
+	return nv.NotificationDB.CreateRoute(route, ctx)
+}
+
+func (nv *notificationValidator) runChannelValFns(channel *NotificationChannel, fns ...channelValFunc) error {
+	for _, fn := range fns {
+		if err := fn(channel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (nv *notificationValidator) hasName(channel *NotificationChannel) error {
+	if channel.Name == "" {
+		return ErrChannelNameRequired
+	}
+	return nil
+}
+
+func (nv *notificationValidator) validTarget(channel *NotificationChannel) error {
+	switch channel.Kind {
+	case ChannelEmail:
+		if _, err := mail.ParseAddressList(channel.Target); err != nil {
+			return ErrInvalidChannelTarget
+		}
+		return nil
+	case ChannelChat, ChannelHTTP:
+		u, err := url.Parse(channel.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidChannelTarget
+		}
+		return nil
+	}
+	return ErrInvalidChannelKind
+}
+
+func (nv *notificationValidator) validTemplates(channel *NotificationChannel) error {
+	if _, err := channel.render(&NotificationData{Alarm: &Alarm{}, Device: &Device{}}); err != nil {
+		return ErrorBadRequest("Invalid Template: " + err.Error())
+	}
+	return nil
+}
+
+// render fills the channel's templates in with data.
+func (channel *NotificationChannel) render(data *NotificationData) (*notify.Message, error) {
+	subject, err := execute(channel.Subject, defaultSubjectTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	body, err := execute(channel.Body, defaultBodyTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	return &notify.Message{Subject: subject, Body: body, ContentType: channel.ContentType}, nil
+}
+
+func execute(text, fallback string, data *NotificationData) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	t, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (na *notificationAuditLogger) ChannelByID(id uint, ctx context.Context) (*NotificationChannel, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "NotificationChannels")
+	return na.NotificationDB.ChannelByID(id, ctx)
+}
+
+func (na *notificationAuditLogger) Channels(ctx context.Context) ([]*NotificationChannel, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "NotificationChannels")
+	return na.NotificationDB.Channels(ctx)
+}
+
+func (na *notificationAuditLogger) CreateChannel(channel *NotificationChannel, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "NotificationChannels")
+	return na.NotificationDB.CreateChannel(channel, ctx)
+}
+
+func (na *notificationAuditLogger) UpdateChannel(channel *NotificationChannel, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogUpdate(uc.UserID, "NotificationChannels")
+	return na.NotificationDB.UpdateChannel(channel, ctx)
+}
+
+func (na *notificationAuditLogger) DeleteChannel(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "NotificationChannels")
+	return na.NotificationDB.DeleteChannel(id, ctx)
+}
+
+func (na *notificationAuditLogger) Routes(ctx context.Context) ([]*NotificationRoute, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "NotificationRoutes")
+	return na.NotificationDB.Routes(ctx)
+}
+
+func (na *notificationAuditLogger) CreateRoute(route *NotificationRoute, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "NotificationRoutes")
+	return na.NotificationDB.CreateRoute(route, ctx)
+}
+
+func (na *notificationAuditLogger) DeleteRoute(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "NotificationRoutes")
+	return na.NotificationDB.DeleteRoute(id, ctx)
+}
+
+// Notifications are another way of subscribing to alarms, so they share the
+// subscriptions role.
+func (na *notificationAuthorization) ChannelByID(id uint, ctx context.Context) (*NotificationChannel, error) {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 1 {
+		return nil, ErrSubscriptionsReadRequired
+	}
+	return na.NotificationDB.ChannelByID(id, ctx)
+}
+func (na *notificationAuthorization) Channels(ctx context.Context) ([]*NotificationChannel, error) {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 1 {
+		return nil, ErrSubscriptionsReadRequired
+	}
+	return na.NotificationDB.Channels(ctx)
+}
+func (na *notificationAuthorization) CreateChannel(channel *NotificationChannel, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 2 {
+		return ErrSubscriptionsWriteRequired
+	}
+	return na.NotificationDB.CreateChannel(channel, ctx)
+}
+func (na *notificationAuthorization) UpdateChannel(channel *NotificationChannel, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 3 {
+		return ErrSubscriptionsUpdateRequired
+	}
+	return na.NotificationDB.UpdateChannel(channel, ctx)
+}
+func (na *notificationAuthorization) DeleteChannel(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 4 {
+		return ErrSubscriptionsDeleteRequired
+	}
+	return na.NotificationDB.DeleteChannel(id, ctx)
+}
+func (na *notificationAuthorization) Routes(ctx context.Context) ([]*NotificationRoute, error) {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 1 {
+		return nil, ErrSubscriptionsReadRequired
+	}
+	return na.NotificationDB.Routes(ctx)
+}
+func (na *notificationAuthorization) CreateRoute(route *NotificationRoute, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 2 {
+		return ErrSubscriptionsWriteRequired
+	}
+	return na.NotificationDB.CreateRoute(route, ctx)
+}
+func (na *notificationAuthorization) DeleteRoute(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 4 {
+		return ErrSubscriptionsDeleteRequired
+	}
+	return na.NotificationDB.DeleteRoute(id, ctx)
+}
+
+// A send is tried NotifyAttempts times before the channel counts as failed,
+// waiting NotifyBackoff after the first try and doubling each time after.
+const (
+	NotifyAttempts = 3
+	NotifyBackoff  = time.Second
+)
+
+// Notifier sends an alarm to every channel routed to receive it. Senders are
+// keyed by channel kind, so they can be swapped for stubs.
+type Notifier struct {
+	db      *gorm.DB
+	senders map[string]notify.Sender
+	backoff time.Duration
+}
+
+func NewNotifier(db *gorm.DB, senders map[string]notify.Sender) *Notifier {
+	return &Notifier{db: db, senders: senders, backoff: NotifyBackoff}
+}
+
+// Notify sends the alarm to each routed channel once, however many of its
+// routes match. A nil notifier sends nothing.
+func (n *Notifier) Notify(action string, alarm *Alarm) error {
+	if n == nil {
+		return nil
+	}
+
+	var channels []*NotificationChannel
+	err := n.db.Where(`id IN (SELECT channel_id FROM notification_routes WHERE deleted_at IS NULL
+		AND (severity = '' OR severity = ?) AND (alarm_type = '' OR alarm_type = ?) AND (device_id = 0 OR device_id = ?))`,
+		alarm.Severity, alarm.Type, alarm.DeviceID).
+		Find(&channels).Error
+	if err != nil || len(channels) == 0 {
+		return err
+	}
+
+	data := &NotificationData{Action: action, Alarm: alarm}
+	if data.Device, err = n.device(alarm.DeviceID); err != nil {
+		return err
+	}
+	return n.sendAll(channels, data)
+}
+
+// Escalate sends an escalation step to its channels, and emails its users
+// with the default templates.
+func (n *Notifier) Escalate(step *EscalationStep, data *NotificationData) error {
+	if n == nil {
+		return nil
+	}
+
+	var channels []*NotificationChannel
+	if len(step.ChannelIDs) > 0 {
+		if err := n.db.Where("id IN (?)", []int64(step.ChannelIDs)).Find(&channels).Error; err != nil {
+			return err
+		}
+	}
+	if len(step.UserIDs) > 0 {
+		var users []*User
+		if err := n.db.Where("id IN (?)", []int64(step.UserIDs)).Find(&users).Error; err != nil {
+			return err
+		}
+		for _, user := range users {
+			channels = append(channels, &NotificationChannel{Kind: ChannelEmail, Target: user.Email})
+		}
+	}
+
+	var err error
+	if data.Device, err = n.device(data.Alarm.DeviceID); err != nil {
+		return err
+	}
+	return n.sendAll(channels, data)
+}
+
+// A device that has since been deleted renders with empty fields.
+func (n *Notifier) device(id uint) (*Device, error) {
+	device := Device{}
+	if err := n.db.Where("id = ?", id).First(&device).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// A channel that fails doesn't stop the others, the last failure is returned.
+func (n *Notifier) sendAll(channels []*NotificationChannel, data *NotificationData) error {
+	var failed error
+	for _, channel := range channels {
+		if err := n.send(channel, data); err != nil {
+			log.Printf("Notification to %s %s: %v", channel.Kind, channel.Target, err)
+			failed = err
+		}
+	}
+	return failed
+}
+
+func (n *Notifier) send(channel *NotificationChannel, data *NotificationData) error {
+	sender, ok := n.senders[channel.Kind]
+	if !ok {
+		return ErrInvalidChannelKind
+	}
+	msg, err := channel.render(data)
+	if err != nil {
+		return err
+	}
+
+	wait := n.backoff
+	for attempt := 1; ; attempt++ {
+		err = sender.Send(channel.Target, msg)
+		if err == nil || attempt == NotifyAttempts {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// alarmNotifications notifies routed channels when an alarm is raised and
+// when it clears. Notifications are sent in the background, so a slow mail
+// server or a retried send never holds up the alarm.
+type alarmNotifications struct {
+	AlarmDB
+	notifier *Notifier
+}
+
+func (an *alarmNotifications) notify(action string, alarm *Alarm) {
+	if an.notifier == nil || alarm.Suppressed {
+		return
+	}
+	copied := *alarm
+	go func() {
+		if err := an.notifier.Notify(action, &copied); err != nil {
+			log.Printf("Notifying alarm %d %s: %v", copied.ID, action, err)
+		}
+	}()
+}
+
+func (an *alarmNotifications) Create(alarm *Alarm, ctx context.Context) error {
+	if err := an.AlarmDB.Create(alarm, ctx); err != nil {
+		return err
+	}
+	if alarm.raised() {
+		an.notify(NotifyRaised, alarm)
+	}
+	return nil
+}
+
+func (an *alarmNotifications) Update(alarm *Alarm, ctx context.Context) error {
+	previous, err := an.AlarmDB.ByID(alarm.ID, ctx)
+	if err != nil {
+		return err
+	}
+	if err := an.AlarmDB.Update(alarm, ctx); err != nil {
+		return err
+	}
+	if previous.Status != AlarmCleared && alarm.Status == AlarmCleared {
+		an.notify(NotifyCleared, alarm)
+	}
+	return nil
+}
+
+func (an *alarmNotifications) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	alarm, err := an.AlarmDB.Transition(id, status, comment, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if alarm.Status == AlarmCleared {
+		an.notify(NotifyCleared, alarm)
+	}
+	return alarm, nil
+}
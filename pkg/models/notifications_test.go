@@ -0,0 +1,173 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/naspinall/Hive/pkg/notify"
+)
+
+var channelColumns = []string{"id", "name", "kind", "target", "subject", "body", "content_type"}
+
+// flakySender fails its first failures sends.
+type flakySender struct {
+	failures int
+	sends    int
+}
+
+func (fs *flakySender) Send(target string, msg *notify.Message) error {
+	fs.sends++
+	if fs.sends <= fs.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestNotifierRoutesAlarm(t *testing.T) {
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		switch {
+		case strings.Contains(query, `FROM "notification_channels"`):
+			return fakeResult{Columns: channelColumns, Rows: [][]driver.Value{
+				{int64(1), "ops", ChannelChat, "https://chat.example.com/ops", "", "", ""},
+				{int64(2), "pager", ChannelHTTP, "https://pager.example.com", "", `{"alarm":{{.Alarm.ID}},"level":{{.Level}}}`, "application/json"},
+			}}
+		case strings.Contains(query, `FROM "devices"`):
+			return fakeResult{Columns: []string{"id", "name"}, Rows: [][]driver.Value{{int64(3), "boiler"}}}
+		}
+		return fakeResult{}
+	})
+	sender := &recordingSender{}
+	n := NewNotifier(db, map[string]notify.Sender{ChannelChat: sender, ChannelHTTP: sender})
+
+	alarm := &Alarm{Model: gorm.Model{ID: 9}, Type: "temperature", Severity: "critical", DeviceID: 3}
+	if err := n.Notify(NotifyRaised, alarm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Routes are matched in the database, a channel with several matching
+	// routes comes back once.
+	routed := fake.Calls(`FROM "notification_channels"`, "id IN (SELECT channel_id FROM notification_routes")
+	if len(routed) != 1 {
+		t.Fatalf("queries %q, want one routed channel lookup", fake.Queries())
+	}
+	if want := []driver.Value{"critical", "temperature", int64(3)}; !reflect.DeepEqual(routed[0].Args, want) {
+		t.Errorf("routed by %v, want %v", routed[0].Args, want)
+	}
+
+	want := []recordedMessage{
+		{Target: "https://chat.example.com/ops", Msg: &notify.Message{
+			Subject: "[critical] temperature RAISED on boiler",
+			Body:    "Alarm 9 temperature was RAISED on boiler with severity critical.",
+		}},
+		{Target: "https://pager.example.com", Msg: &notify.Message{
+			Subject:     "[critical] temperature RAISED on boiler",
+			Body:        `{"alarm":9,"level":0}`,
+			ContentType: "application/json",
+		}},
+	}
+	if got := sender.Sent(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sent %+v, want %+v", got, want)
+	}
+}
+
+func TestNotifierWithoutRoutesSendsNothing(t *testing.T) {
+	db, fake := newFakeGorm(t, nil)
+	sender := &recordingSender{}
+	n := NewNotifier(db, map[string]notify.Sender{ChannelChat: sender})
+
+	if err := n.Notify(NotifyCleared, &Alarm{Model: gorm.Model{ID: 9}, DeviceID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.Sent()) != 0 || len(fake.Calls(`FROM "devices"`)) != 0 {
+		t.Errorf("sent %+v after %q, want nothing", sender.Sent(), fake.Queries())
+	}
+}
+
+func TestNotifierRetriesSends(t *testing.T) {
+	cases := []struct {
+		name     string
+		failures int
+		sends    int
+		failed   bool
+	}{
+		{"first try", 0, 1, false},
+		{"after a failure", 1, 2, false},
+		{"on the last try", NotifyAttempts - 1, NotifyAttempts, false},
+		{"gives up", NotifyAttempts + 1, NotifyAttempts, true},
+	}
+	for _, c := range cases {
+		sender := &flakySender{failures: c.failures}
+		n := &Notifier{senders: map[string]notify.Sender{ChannelChat: sender}}
+
+		channel := &NotificationChannel{Kind: ChannelChat, Target: "https://chat.example.com/ops"}
+		err := n.send(channel, &NotificationData{Action: NotifyRaised, Alarm: &Alarm{}, Device: &Device{}})
+		if (err != nil) != c.failed || sender.sends != c.sends {
+			t.Errorf("%s: %d sends and error %v, want %d sends and failed %v", c.name, sender.sends, err, c.sends, c.failed)
+		}
+	}
+}
+
+func TestNotifierDoesntRetryUnsendable(t *testing.T) {
+	sender := &flakySender{}
+	n := &Notifier{senders: map[string]notify.Sender{ChannelChat: sender}}
+	data := &NotificationData{Action: NotifyRaised, Alarm: &Alarm{}, Device: &Device{}}
+
+	if err := n.send(&NotificationChannel{Kind: ChannelEmail}, data); err != ErrInvalidChannelKind {
+		t.Errorf("unknown kind: error %v, want %v", err, ErrInvalidChannelKind)
+	}
+	if err := n.send(&NotificationChannel{Kind: ChannelChat, Body: "{{.Alarm.Missing}}"}, data); err == nil {
+		t.Error("bad template: sent, want an error")
+	}
+	if sender.sends != 0 {
+		t.Errorf("%d sends, want none", sender.sends)
+	}
+}
+
+func TestChannelRender(t *testing.T) {
+	data := &NotificationData{
+		Action: NotifyRaised,
+		Alarm:  &Alarm{Model: gorm.Model{ID: 9}, Type: "temperature", Severity: "major"},
+		Device: &Device{Name: "boiler"},
+		Level:  2,
+	}
+	cases := []struct {
+		name          string
+		channel       NotificationChannel
+		subject, body string
+		failed        bool
+	}{
+		{
+			name:    "defaults",
+			subject: "[major] temperature RAISED on boiler",
+			body:    "Alarm 9 temperature was RAISED on boiler with severity major.",
+		},
+		{
+			name:    "custom",
+			channel: NotificationChannel{Subject: "{{.Device.Name}} level {{.Level}}", Body: "{{.Alarm.Type}}\n{{.Action}}"},
+			subject: "boiler level 2",
+			body:    "temperature\nRAISED",
+		},
+		{name: "unknown field", channel: NotificationChannel{Subject: "{{.Alarm.Missing}}"}, failed: true},
+		{name: "bad syntax", channel: NotificationChannel{Body: "{{.Alarm.Type"}, failed: true},
+	}
+	for _, c := range cases {
+		msg, err := c.channel.render(data)
+		if c.failed {
+			if err == nil {
+				t.Errorf("%s: rendered %+v, want an error", c.name, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if msg.Subject != c.subject || msg.Body != c.body {
+			t.Errorf("%s: rendered %q and %q, want %q and %q", c.name, msg.Subject, msg.Body, c.subject, c.body)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// PageQuery pages through a list in ID order. Cursor is the Next value of
+// the previous page, and is empty for the first page.
+type PageQuery struct {
+	Limit  int
+	Cursor string
+}
+
+// scope orders db by ID and limits it to the page, fetching one extra row to
+// know if there is another page.
+func (p *PageQuery) scope(db *gorm.DB) (*gorm.DB, error) {
+	p.clamp()
+	if p.Cursor != "" {
+		lastID, err := decodeIDCursor(p.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("id > ?", lastID)
+	}
+	return db.Order("id").Limit(p.Limit + 1), nil
+}
+
+// clamp keeps the limit between one and MaxPageLimit, defaulting to
+// DefaultPageLimit.
+func (p *PageQuery) clamp() {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+}
+
+// more reports whether a page of count rows has another page after it, and
+// how many of the rows belong to this page.
+func (p *PageQuery) more(count int) (int, bool) {
+	if count > p.Limit {
+		return p.Limit, true
+	}
+	return count, false
+}
+
+func encodeIDCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeIDCursor(cursor string) (uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 32)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/naspinall/Hive/pkg/remotewrite"
+)
+
+const DefaultDeviceLabel = "hive_device"
+
+// PrometheusWriter stores the samples of a Prometheus remote write request as
+// measurements. Each series names its device with DeviceLabel, and the metric
+// name is the measurement type.
+type PrometheusWriter struct {
+	ds          DeviceService
+	ms          MeasurementService
+	DeviceLabel string
+	UnitLabel   string
+}
+
+func NewPrometheusWriter(ds DeviceService, ms MeasurementService, deviceLabel string) *PrometheusWriter {
+	if deviceLabel == "" {
+		deviceLabel = DefaultDeviceLabel
+	}
+	return &PrometheusWriter{
+		ds:          ds,
+		ms:          ms,
+		DeviceLabel: deviceLabel,
+		UnitLabel:   DefaultUnitTag,
+	}
+}
+
+// Write stores every sample of req, the report counts series rather than
+// samples, with Row being the series' position in the request from one.
+func (pw *PrometheusWriter) Write(req *remotewrite.WriteRequest, ctx context.Context) (*ImportReport, error) {
+	report := &ImportReport{Errors: []ImportRowError{}}
+	devices := newDeviceResolver(pw.ds)
+	failed := map[int]bool{}
+	fail := func(row int, message string) {
+		if failed[row] {
+			return
+		}
+		failed[row] = true
+		report.fail(row, message)
+	}
+
+	var batch []*Measurement
+	var batchRows []int
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := pw.ms.CreateBatch(batch, ctx)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				fail(batchRows[result.Index], result.Error)
+			}
+		}
+		batch, batchRows = nil, nil
+		return nil
+	}
+
+	for i := range req.Timeseries {
+		report.Rows++
+		row := report.Rows
+
+		measurements, err := pw.measurements(&req.Timeseries[i], devices, ctx)
+		if err != nil {
+			if _, ok := err.(ErrorUnauthorized); ok {
+				return nil, err
+			}
+			fail(row, err.Error())
+			continue
+		}
+
+		for _, measurement := range measurements {
+			batch = append(batch, measurement)
+			batchRows = append(batchRows, row)
+			if len(batch) >= DefaultImportBatchSize {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	report.Imported = report.Rows - report.Failed
+	return report, nil
+}
+
+func (pw *PrometheusWriter) measurements(ts *remotewrite.TimeSeries, devices *deviceResolver, ctx context.Context) ([]*Measurement, error) {
+	metric := ts.Label(remotewrite.MetricNameLabel)
+	if metric == "" {
+		return nil, ErrTypeRequired
+	}
+	name := ts.Label(pw.DeviceLabel)
+	if name == "" {
+		return nil, ErrorBadRequest(fmt.Sprintf("Missing Label %s", pw.DeviceLabel))
+	}
+	deviceID, err := devices.resolve(name, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := ts.Label(pw.UnitLabel)
+	measurements := make([]*Measurement, 0, len(ts.Samples))
+	for _, sample := range ts.Samples {
+		// Prometheus marks stale series with a NaN, and infinities can't be
+		// stored, so neither is kept.
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue
+		}
+		measurements = append(measurements, &Measurement{
+			DeviceID:   deviceID,
+			Type:       metric,
+			Value:      sample.Value,
+			Unit:       unit,
+			ObservedAt: time.Unix(0, sample.Timestamp*int64(time.Millisecond)).UTC(),
+		})
+	}
+	return measurements, nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/naspinall/Hive/pkg/remotewrite"
+)
+
+func TestPrometheusSkipsNonFiniteSamples(t *testing.T) {
+	pw := &PrometheusWriter{DeviceLabel: DefaultDeviceLabel, UnitLabel: "unit"}
+	devices := &deviceResolver{ids: map[string]uint{"boiler": 3}}
+	ts := &remotewrite.TimeSeries{
+		Labels: []remotewrite.Label{
+			{Name: remotewrite.MetricNameLabel, Value: "temperature"},
+			{Name: DefaultDeviceLabel, Value: "boiler"},
+			{Name: "unit", Value: "Cel"},
+		},
+		Samples: []remotewrite.Sample{
+			{Value: 21.5, Timestamp: 1000},
+			{Value: math.NaN(), Timestamp: 2000},
+			{Value: math.Inf(1), Timestamp: 3000},
+			{Value: math.Inf(-1), Timestamp: 4000},
+			{Value: -273.15, Timestamp: 5000},
+			{Value: math.MaxFloat64, Timestamp: 6000},
+		},
+	}
+
+	measurements, err := pw.measurements(ts, devices, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, m := range measurements {
+		if m.DeviceID != 3 || m.Type != "temperature" || m.Unit != "Cel" {
+			t.Errorf("measurement %+v", m)
+		}
+		got = append(got, m.ObservedAt.UnixNano()/1e6)
+	}
+	if len(got) != 3 || got[0] != 1000 || got[1] != 5000 || got[2] != 6000 {
+		t.Errorf("kept samples at %v, want 1000, 5000 and 6000", got)
+	}
+}
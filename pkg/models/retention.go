@@ -0,0 +1,216 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+)
+
+// RetentionPolicy controls how long raw measurements and each rollup
+// resolution are kept. A policy can be scoped to a device, a measurement type
+// or both, the most specific policy wins. Zero days keeps data forever.
+type RetentionPolicy struct {
+	gorm.Model
+	DeviceID       uint   `gorm:"unique_index:idx_retention_scope" json:"deviceId"`
+	Type           string `gorm:"unique_index:idx_retention_scope" json:"type"`
+	RawDays        uint   `json:"rawDays"`
+	FiveMinuteDays uint   `json:"fiveMinuteDays"`
+	HourlyDays     uint   `json:"hourlyDays"`
+	DailyDays      uint   `json:"dailyDays"`
+}
+
+type retentionGorm struct {
+	db *gorm.DB
+}
+
+type retentionAuditLogger struct {
+	RetentionDB
+}
+
+type retentionAuthorization struct {
+	RetentionDB
+}
+
+type RetentionService interface {
+	RetentionDB
+}
+
+type RetentionDB interface {
+	ByID(id uint, ctx context.Context) (*RetentionPolicy, error)
+	Many(ctx context.Context) ([]*RetentionPolicy, error)
+	Create(policy *RetentionPolicy, ctx context.Context) error
+	Update(policy *RetentionPolicy, ctx context.Context) error
+	Delete(id uint, ctx context.Context) error
+}
+
+func NewRetentionService(db *gorm.DB) RetentionService {
+	return &retentionAuthorization{
+		&retentionAuditLogger{
+			&retentionGorm{
+				db: db,
+			},
+		},
+	}
+}
+
+// Policies scoped to a device are more specific than those scoped to a type,
+// a policy scoped to both is the most specific.
+func (p *RetentionPolicy) specificity() int {
+	s := 0
+	if p.DeviceID != 0 {
+		s += 2
+	}
+	if p.Type != "" {
+		s++
+	}
+	return s
+}
+
+// days is how long the policy keeps a rollup resolution, or raw measurements
+// when resolution is empty.
+func (p *RetentionPolicy) days(resolution string) uint {
+	switch resolution {
+	case "":
+		return p.RawDays
+	case "5m":
+		return p.FiveMinuteDays
+	case "1h":
+		return p.HourlyDays
+	case "1d":
+		return p.DailyDays
+	}
+	return 0
+}
+
+// governingPolicy returns the most specific of the policies covering a
+// device's measurements of a type, or nil if none do.
+func governingPolicy(policies []*RetentionPolicy, deviceID uint, typ string) *RetentionPolicy {
+	var governing *RetentionPolicy
+	for _, p := range policies {
+		if (p.DeviceID != 0 && p.DeviceID != deviceID) || (p.Type != "" && p.Type != typ) {
+			continue
+		}
+		if governing == nil || p.specificity() > governing.specificity() {
+			governing = p
+		}
+	}
+	return governing
+}
+
+func (rg *retentionGorm) ByID(id uint, ctx context.Context) (*RetentionPolicy, error) {
+	var policy RetentionPolicy
+	if err := rg.db.Where("id = ?", id).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (rg *retentionGorm) Many(ctx context.Context) ([]*RetentionPolicy, error) {
+	var policies []*RetentionPolicy
+	if err := rg.db.Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (rg *retentionGorm) Create(policy *RetentionPolicy, ctx context.Context) error {
+	return rg.db.Create(policy).Error
+}
+
+func (rg *retentionGorm) Update(policy *RetentionPolicy, ctx context.Context) error {
+	return rg.db.Save(policy).Error
+}
+
+func (rg *retentionGorm) Delete(id uint, ctx context.Context) error {
+	policy := RetentionPolicy{Model: gorm.Model{ID: id}}
+	return rg.db.Delete(&policy).Error
+}
+
+//Getters
+func (ra *retentionAuditLogger) ByID(id uint, ctx context.Context) (*RetentionPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "RetentionPolicies")
+	return ra.RetentionDB.ByID(id, ctx)
+}
+
+func (ra *retentionAuditLogger) Many(ctx context.Context) ([]*RetentionPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "RetentionPolicies")
+	return ra.RetentionDB.Many(ctx)
+}
+
+//Mutators
+func (ra *retentionAuditLogger) Create(policy *RetentionPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "RetentionPolicies")
+	return ra.RetentionDB.Create(policy, ctx)
+}
+
+func (ra *retentionAuditLogger) Update(policy *RetentionPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogUpdate(uc.UserID, "RetentionPolicies")
+	return ra.RetentionDB.Update(policy, ctx)
+}
+
+func (ra *retentionAuditLogger) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "RetentionPolicies")
+	return ra.RetentionDB.Delete(id, ctx)
+}
+
+// Retention policies govern measurement data, so they share its role.
+func (ra *retentionAuthorization) ByID(id uint, ctx context.Context) (*RetentionPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 1 {
+		return nil, ErrMeasurementReadRequired
+	}
+	return ra.RetentionDB.ByID(id, ctx)
+}
+func (ra *retentionAuthorization) Many(ctx context.Context) ([]*RetentionPolicy, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 1 {
+		return nil, ErrMeasurementReadRequired
+	}
+	return ra.RetentionDB.Many(ctx)
+}
+func (ra *retentionAuthorization) Create(policy *RetentionPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 2 {
+		return ErrMeasurementWriteRequired
+	}
+	return ra.RetentionDB.Create(policy, ctx)
+}
+func (ra *retentionAuthorization) Update(policy *RetentionPolicy, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 3 {
+		return ErrMeasurementUpdateRequired
+	}
+	return ra.RetentionDB.Update(policy, ctx)
+}
+func (ra *retentionAuthorization) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 4 {
+		return ErrMeasurementDeleteRequired
+	}
+	return ra.RetentionDB.Delete(id, ctx)
+}
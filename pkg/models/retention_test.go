@@ -0,0 +1,103 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+var policyColumns = []string{"id", "created_at", "updated_at", "deleted_at", "device_id", "type", "raw_days", "five_minute_days", "hourly_days", "daily_days"}
+
+func policyRow(id, deviceID int64, typ string, raw, fiveMinute, hourly, daily int64) []driver.Value {
+	return []driver.Value{id, rollupNow, rollupNow, nil, deviceID, typ, raw, fiveMinute, hourly, daily}
+}
+
+func policyResponder(rows ...[]driver.Value) func(string, []driver.Value) fakeResult {
+	return func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, `FROM "retention_policies"`) {
+			return fakeResult{Columns: policyColumns, Rows: rows}
+		}
+		return fakeResult{}
+	}
+}
+
+// Reads must not open transactions they never finish, each one holds a
+// pooled connection until the process exits.
+func TestRetentionReadsDontOpenTransactions(t *testing.T) {
+	db, fake := newFakeGorm(t, policyResponder(policyRow(1, 0, "", 30, 0, 0, 0)))
+	rg := &retentionGorm{db: db}
+
+	if _, err := rg.ByID(1, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rg.Many(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if indexOf(fake.Queries(), "BEGIN") >= 0 {
+		t.Errorf("reads began a transaction: %q", fake.Queries())
+	}
+}
+
+func TestPurgeLeavesRowsToMoreSpecificPolicies(t *testing.T) {
+	db, fake := newFakeGorm(t, policyResponder(
+		policyRow(1, 0, "", 30, 90, 0, 0),
+		policyRow(2, 7, "", 7, 0, 0, 0),
+		policyRow(3, 0, "temperature", 14, 0, 0, 0),
+		policyRow(4, 7, "temperature", 1, 0, 0, 0),
+	))
+
+	if err := NewRollupWorker(db, 0).Run(rollupNow); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := fake.Calls(`DELETE FROM "measurements"`)
+	if len(raw) != 4 {
+		t.Fatalf("purged raw measurements %d times, want 4", len(raw))
+	}
+	cases := []struct {
+		days     int
+		contains []string
+	}{
+		{30, []string{"device_id <> ", "type <> ", "NOT (device_id = $"}},
+		{7, []string{"(device_id = $", "NOT (device_id = $"}},
+		{14, []string{"(type = $", "device_id <> ", "NOT (device_id = $"}},
+		{1, []string{"(device_id = $", "(type = $"}},
+	}
+	for i, c := range cases {
+		query := raw[i].Query
+		for _, fragment := range c.contains {
+			if !strings.Contains(query, fragment) {
+				t.Errorf("purge %d: %q doesn't contain %q", i, query, fragment)
+			}
+		}
+		cutoff := rollupNow.Add(-time.Duration(c.days) * 24 * time.Hour)
+		if got := raw[i].Args[len(raw[i].Args)-1].(time.Time); !got.Equal(cutoff) {
+			t.Errorf("purge %d: cutoff %v, want %v", i, got, cutoff)
+		}
+	}
+	// The most specific policy has nothing left to other policies.
+	if strings.Contains(raw[3].Query, "NOT") || strings.Contains(raw[3].Query, "<>") {
+		t.Errorf("most specific purge excludes rows: %q", raw[3].Query)
+	}
+
+	rollups := fake.Calls(`"measurement_rollups"`, "DELETE")
+	if len(rollups) != 1 || rollups[0].Args[len(rollups[0].Args)-2] != "5m" {
+		t.Errorf("purged rollups %v, want only 5m", rollups)
+	}
+}
+
+func TestPolicySpecificity(t *testing.T) {
+	global := &RetentionPolicy{}
+	byType := &RetentionPolicy{Type: "temperature"}
+	byDevice := &RetentionPolicy{DeviceID: 7}
+	both := &RetentionPolicy{DeviceID: 7, Type: "temperature"}
+
+	order := []*RetentionPolicy{global, byType, byDevice, both}
+	for i := 1; i < len(order); i++ {
+		if order[i-1].specificity() >= order[i].specificity() {
+			t.Errorf("policy %d isn't more specific than policy %d", i, i-1)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// MeasurementRollup summarises every measurement of a type sent by a device
+// within a single bucket of a rollup resolution.
+type MeasurementRollup struct {
+	ID          uint      `gorm:"primary_key" json:"-"`
+	DeviceID    uint      `gorm:"unique_index:idx_rollup_bucket" json:"deviceId"`
+	Type        string    `gorm:"unique_index:idx_rollup_bucket" json:"type"`
+	Resolution  string    `gorm:"unique_index:idx_rollup_bucket" json:"resolution"`
+	BucketStart time.Time `gorm:"unique_index:idx_rollup_bucket" json:"bucketStart"`
+	Unit        string    `json:"unit"`
+	Count       int64     `json:"count"`
+	Total       float64   `json:"total"`
+	Minimum     float64   `json:"minimum"`
+	Maximum     float64   `json:"maximum"`
+	FirstValue  float64   `json:"firstValue"`
+	LastValue   float64   `json:"lastValue"`
+	FirstAt     time.Time `json:"firstAt"`
+	LastAt      time.Time `json:"lastAt"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+type rollupResolution struct {
+	Name   string
+	Width  time.Duration
+	Source string
+}
+
+// Rollup resolutions from finest to coarsest, each is computed from the one
+// before it so raw data only needs to be kept until the first rollup.
+var rollupResolutions = []rollupResolution{
+	{Name: "5m", Width: 5 * time.Minute},
+	{Name: "1h", Width: time.Hour, Source: "5m"},
+	{Name: "1d", Width: 24 * time.Hour, Source: "1h"},
+}
+
+// Returns the coarsest rollup that can be re-bucketed into width, or nil if
+// width needs raw measurements.
+func rollupFor(width time.Duration) *rollupResolution {
+	for i := len(rollupResolutions) - 1; i >= 0; i-- {
+		r := rollupResolutions[i]
+		if width >= r.Width && width%r.Width == 0 {
+			return &r
+		}
+	}
+	return nil
+}
+
+// A rollup's source rows are the raw measurements, or the rollups of its
+// source resolution. Each query below reads the buckets whose source rows
+// were written since the resolution's watermark, or that closed since it,
+// ignoring buckets that are still open.
+const rollupTouchedRaw = `
+SELECT DISTINCT device_id, type, floor(extract(epoch from observed_at) / %[1]d)::bigint * %[1]d
+FROM measurements
+WHERE deleted_at IS NULL AND (
+	(created_at >= ? AND created_at < ? AND observed_at < ?)
+	OR (observed_at >= ? AND observed_at < ?))`
+
+const rollupTouchedRollup = `
+SELECT DISTINCT device_id, type, floor(extract(epoch from bucket_start) / %[1]d)::bigint * %[1]d
+FROM measurement_rollups
+WHERE resolution = ? AND (
+	(updated_at >= ? AND updated_at < ? AND bucket_start < ?)
+	OR (bucket_start >= ? AND bucket_start < ?))`
+
+// The buckets to compute are given as arrays of device IDs, types and bucket
+// starts in epoch seconds.
+const rollupBuckets = `
+WITH buckets AS (
+	SELECT device_id, type, to_timestamp(start) AS bucket_start
+	FROM unnest(?::bigint[], ?::text[], ?::bigint[]) AS b(device_id, type, start)
+)
+`
+
+const rollupFromRaw = rollupBuckets + `
+INSERT INTO measurement_rollups
+	(device_id, type, resolution, bucket_start, unit, count, total, minimum, maximum, first_value, last_value, first_at, last_at, updated_at)
+SELECT m.device_id, m.type, ?, b.bucket_start,
+	max(m.unit), count(*), sum(m.value), min(m.value), max(m.value),
+	(array_agg(m.value ORDER BY m.observed_at ASC, m.id ASC))[1],
+	(array_agg(m.value ORDER BY m.observed_at DESC, m.id DESC))[1],
+	min(m.observed_at), max(m.observed_at), ?
+FROM buckets b
+JOIN measurements m ON m.device_id = b.device_id AND m.type = b.type
+	AND m.observed_at >= b.bucket_start AND m.observed_at < b.bucket_start + %[1]d * interval '1 second'
+WHERE m.deleted_at IS NULL
+GROUP BY m.device_id, m.type, b.bucket_start
+` + rollupUpsert
+
+const rollupFromRollup = rollupBuckets + `
+INSERT INTO measurement_rollups
+	(device_id, type, resolution, bucket_start, unit, count, total, minimum, maximum, first_value, last_value, first_at, last_at, updated_at)
+SELECT r.device_id, r.type, ?, b.bucket_start,
+	max(r.unit), sum(r.count), sum(r.total), min(r.minimum), max(r.maximum),
+	(array_agg(r.first_value ORDER BY r.first_at ASC))[1],
+	(array_agg(r.last_value ORDER BY r.last_at DESC))[1],
+	min(r.first_at), max(r.last_at), ?
+FROM buckets b
+JOIN measurement_rollups r ON r.device_id = b.device_id AND r.type = b.type
+	AND r.bucket_start >= b.bucket_start AND r.bucket_start < b.bucket_start + %[1]d * interval '1 second'
+WHERE r.resolution = ?
+GROUP BY r.device_id, r.type, b.bucket_start
+` + rollupUpsert
+
+const rollupUpsert = `
+ON CONFLICT (device_id, type, resolution, bucket_start) DO UPDATE SET
+	unit = EXCLUDED.unit, count = EXCLUDED.count, total = EXCLUDED.total,
+	minimum = EXCLUDED.minimum, maximum = EXCLUDED.maximum,
+	first_value = EXCLUDED.first_value, last_value = EXCLUDED.last_value,
+	first_at = EXCLUDED.first_at, last_at = EXCLUDED.last_at,
+	updated_at = EXCLUDED.updated_at`
+
+// rollupBatch bounds how many buckets are computed by a single statement.
+const rollupBatch = 1000
+
+// RollupWatermark records how far a resolution has read its source rows. It
+// is stored so late measurements that arrive while the worker is down are
+// still rolled up once it restarts.
+type RollupWatermark struct {
+	Resolution string    `gorm:"primary_key"`
+	Through    time.Time `gorm:"not null"`
+}
+
+// rollupBucket is a bucket of a device's measurements of one type.
+type rollupBucket struct {
+	DeviceID uint
+	Type     string
+	Start    time.Time
+}
+
+// RollupWorker periodically computes measurement rollups and purges data that
+// has outlived its retention policy.
+type RollupWorker struct {
+	db       *gorm.DB
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+const DefaultRollupInterval = 5 * time.Minute
+
+// RollupSettle is how long the worker waits before reading a new
+// measurement, so rows from transactions still in flight aren't skipped.
+const RollupSettle = time.Minute
+
+func NewRollupWorker(db *gorm.DB, interval time.Duration) *RollupWorker {
+	if interval <= 0 {
+		interval = DefaultRollupInterval
+	}
+	return &RollupWorker{
+		db:       db,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (rw *RollupWorker) Start() {
+	rw.wg.Add(1)
+	go func() {
+		defer rw.wg.Done()
+		ticker := time.NewTicker(rw.interval)
+		defer ticker.Stop()
+		for {
+			if err := rw.Run(time.Now()); err != nil {
+				log.Println(err)
+			}
+			select {
+			case <-rw.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (rw *RollupWorker) Stop() {
+	close(rw.stop)
+	rw.wg.Wait()
+}
+
+// Run computes every rollup bucket whose source changed since the last run,
+// then applies the retention policies. Rollups always run first so raw data
+// is never purged before it has been summarised.
+func (rw *RollupWorker) Run(now time.Time) error {
+	// Postgres keeps microseconds, so watermarks compare exactly.
+	now = now.UTC().Truncate(time.Microsecond)
+	var policies []*RetentionPolicy
+	if err := rw.db.Find(&policies).Error; err != nil {
+		return err
+	}
+
+	for _, r := range rollupResolutions {
+		if err := rw.rollup(r, policies, now); err != nil {
+			return err
+		}
+	}
+	return rw.purge(policies, now)
+}
+
+// Computes the buckets of r whose source rows were written since its
+// watermark, and moves the watermark on to RollupSettle ago. Rollups written
+// by this run are stamped now, so the next run of a coarser resolution reads
+// them again. A bucket closed by this run is computed here for every
+// resolution, finest first.
+func (rw *RollupWorker) rollup(r rollupResolution, policies []*RetentionPolicy, now time.Time) error {
+	watermark := RollupWatermark{Resolution: r.Name}
+	if err := rw.db.Where("resolution = ?", r.Name).FirstOrInit(&watermark).Error; err != nil {
+		return err
+	}
+	from, through := watermark.Through, now.Add(-RollupSettle)
+	if !from.Before(through) {
+		return nil
+	}
+
+	buckets, err := rw.touched(r, from, through)
+	if err != nil {
+		return err
+	}
+	buckets = retained(buckets, r, policies, now)
+
+	tx := rw.db.Begin()
+	for len(buckets) > 0 {
+		n := len(buckets)
+		if n > rollupBatch {
+			n = rollupBatch
+		}
+		if err := rw.compute(tx, r, buckets[:n], now); err != nil {
+			tx.Rollback()
+			return err
+		}
+		buckets = buckets[n:]
+	}
+	watermark.Through = through
+	if err := tx.Save(&watermark).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Lists the buckets of r that need computing, those with source rows written
+// in [from, through) and those that closed in that time.
+func (rw *RollupWorker) touched(r rollupResolution, from, through time.Time) ([]rollupBucket, error) {
+	seconds := int64(r.Width / time.Second)
+	opened, closed := from.Truncate(r.Width), through.Truncate(r.Width)
+
+	var rows *sql.Rows
+	var err error
+	if r.Source == "" {
+		rows, err = rw.db.Raw(fmt.Sprintf(rollupTouchedRaw, seconds), from, through, closed, opened, closed).Rows()
+	} else {
+		rows, err = rw.db.Raw(fmt.Sprintf(rollupTouchedRollup, seconds), r.Source, from, through, closed, opened, closed).Rows()
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []rollupBucket
+	for rows.Next() {
+		var bucket rollupBucket
+		var start int64
+		if err := rows.Scan(&bucket.DeviceID, &bucket.Type, &start); err != nil {
+			return nil, err
+		}
+		bucket.Start = time.Unix(start, 0).UTC()
+		buckets = append(buckets, bucket)
+	}
+	return buckets, rows.Err()
+}
+
+// retained drops the buckets that start before their source's retention
+// cutoff. Some of their source rows may have been purged, so recomputing them
+// would replace a complete rollup with a partial one.
+func retained(buckets []rollupBucket, r rollupResolution, policies []*RetentionPolicy, now time.Time) []rollupBucket {
+	kept := buckets[:0]
+	for _, bucket := range buckets {
+		if p := governingPolicy(policies, bucket.DeviceID, bucket.Type); p != nil {
+			days := p.days(r.Source)
+			if days != 0 && bucket.Start.Before(now.Add(-time.Duration(days)*24*time.Hour)) {
+				continue
+			}
+		}
+		kept = append(kept, bucket)
+	}
+	return kept
+}
+
+// Recomputes each of the buckets from all of its source rows.
+func (rw *RollupWorker) compute(tx *gorm.DB, r rollupResolution, buckets []rollupBucket, now time.Time) error {
+	devices := make(pq.Int64Array, len(buckets))
+	types := make(pq.StringArray, len(buckets))
+	starts := make(pq.Int64Array, len(buckets))
+	for i, bucket := range buckets {
+		devices[i] = int64(bucket.DeviceID)
+		types[i] = bucket.Type
+		starts[i] = bucket.Start.Unix()
+	}
+
+	seconds := int64(r.Width / time.Second)
+	if r.Source == "" {
+		return tx.Exec(fmt.Sprintf(rollupFromRaw, seconds), devices, types, starts, r.Name, now).Error
+	}
+	return tx.Exec(fmt.Sprintf(rollupFromRollup, seconds), devices, types, starts, r.Name, now, r.Source).Error
+}
+
+func (rw *RollupWorker) purge(policies []*RetentionPolicy, now time.Time) error {
+	for _, p := range policies {
+		// Rows covered by a more specific policy are left for that policy.
+		scope := func(db *gorm.DB) *gorm.DB {
+			if p.DeviceID != 0 {
+				db = db.Where("device_id = ?", p.DeviceID)
+			}
+			if p.Type != "" {
+				db = db.Where("type = ?", p.Type)
+			}
+			for _, other := range policies {
+				if other.specificity() <= p.specificity() {
+					continue
+				}
+				switch {
+				case other.DeviceID != 0 && other.Type != "":
+					db = db.Where("NOT (device_id = ? AND type = ?)", other.DeviceID, other.Type)
+				case other.DeviceID != 0:
+					db = db.Where("device_id <> ?", other.DeviceID)
+				default:
+					db = db.Where("type <> ?", other.Type)
+				}
+			}
+			return db
+		}
+
+		if p.RawDays != 0 {
+			cutoff := now.Add(-time.Duration(p.RawDays) * 24 * time.Hour)
+			if err := rw.db.Unscoped().Scopes(scope).Where("observed_at < ?", cutoff).Delete(&Measurement{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, r := range rollupResolutions {
+			resolution, days := r.Name, p.days(r.Name)
+			if days == 0 {
+				continue
+			}
+			cutoff := now.Add(-time.Duration(days) * 24 * time.Hour)
+			if err := rw.db.Scopes(scope).Where("resolution = ? AND bucket_start < ?", resolution, cutoff).Delete(&MeasurementRollup{}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var rollupNow = time.Date(2026, 10, 18, 10, 7, 30, 0, time.UTC)
+
+// touchedResponder answers the bucket listing of each resolution with the
+// buckets given for it.
+func touchedResponder(touched map[string][][]driver.Value) func(string, []driver.Value) fakeResult {
+	return func(query string, args []driver.Value) fakeResult {
+		if !strings.Contains(query, "SELECT DISTINCT") {
+			return fakeResult{}
+		}
+		resolution := "5m"
+		if strings.Contains(query, "FROM measurement_rollups") {
+			resolution = map[string]string{"5m": "1h", "1h": "1d"}[args[0].(string)]
+		}
+		return fakeResult{Columns: []string{"device_id", "type", "start"}, Rows: touched[resolution]}
+	}
+}
+
+func TestRollupFirstRunReadsEverything(t *testing.T) {
+	bucket := rollupNow.Add(-time.Hour).Truncate(5 * time.Minute)
+	db, fake := newFakeGorm(t, touchedResponder(map[string][][]driver.Value{
+		"5m": {{int64(1), "temperature", bucket.Unix()}, {int64(2), "humidity", bucket.Unix()}},
+	}))
+
+	if err := NewRollupWorker(db, 0).rollup(rollupResolutions[0], nil, rollupNow); err != nil {
+		t.Fatal(err)
+	}
+
+	through := rollupNow.Add(-RollupSettle)
+	touched := fake.Calls("SELECT DISTINCT", "FROM measurements")
+	if len(touched) != 1 {
+		t.Fatalf("listed buckets %d times, want 1", len(touched))
+	}
+	want := []driver.Value{time.Time{}, through, through.Truncate(5 * time.Minute), time.Time{}, through.Truncate(5 * time.Minute)}
+	for i, arg := range touched[0].Args {
+		if !arg.(time.Time).Equal(want[i].(time.Time)) {
+			t.Errorf("listing arg %d = %v, want %v", i, arg, want[i])
+		}
+	}
+
+	computed := fake.Calls("INSERT INTO measurement_rollups")
+	if len(computed) != 1 {
+		t.Fatalf("computed %d times, want 1", len(computed))
+	}
+	args := computed[0].Args
+	if args[0] != "{1,2}" || args[1] != `{"temperature","humidity"}` || args[2] != "{"+strconv.FormatInt(bucket.Unix(), 10)+","+strconv.FormatInt(bucket.Unix(), 10)+"}" {
+		t.Errorf("computed buckets %v", args[:3])
+	}
+	if args[3] != "5m" || !args[4].(time.Time).Equal(rollupNow) {
+		t.Errorf("computed resolution %v stamped %v", args[3], args[4])
+	}
+
+	saved := fake.Calls("rollup_watermarks", "UPDATE")
+	if len(saved) != 1 || !saved[0].Args[0].(time.Time).Equal(through) {
+		t.Fatalf("watermark saved as %v, want %v", saved, through)
+	}
+
+	// The rollups and watermark are written together.
+	queries := fake.Queries()
+	begin, commit := indexOf(queries, "BEGIN"), indexOf(queries, "COMMIT")
+	insert, update := indexOf(queries, "INSERT INTO measurement_rollups"), indexOf(queries, `UPDATE "rollup_watermarks"`)
+	if !(begin < insert && insert < update && update < commit) {
+		t.Errorf("statements out of order: %q", queries)
+	}
+}
+
+func TestRollupResumesFromWatermark(t *testing.T) {
+	from := rollupNow.Add(-6 * time.Minute)
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, `FROM "rollup_watermarks"`) {
+			return fakeResult{Columns: []string{"resolution", "through"}, Rows: [][]driver.Value{{"5m", from}}}
+		}
+		return fakeResult{}
+	})
+
+	if err := NewRollupWorker(db, 0).rollup(rollupResolutions[0], nil, rollupNow); err != nil {
+		t.Fatal(err)
+	}
+
+	touched := fake.Calls("SELECT DISTINCT")
+	if len(touched) != 1 {
+		t.Fatalf("listed buckets %d times, want 1", len(touched))
+	}
+	// Late rows are found by when they were written, not when observed.
+	if got := touched[0].Args[0].(time.Time); !got.Equal(from) {
+		t.Errorf("read rows written from %v, want %v", got, from)
+	}
+	if len(fake.Calls("INSERT INTO measurement_rollups")) != 0 {
+		t.Error("computed rollups with no buckets touched")
+	}
+}
+
+func TestRollupCaughtUp(t *testing.T) {
+	through := rollupNow.Add(-RollupSettle)
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, `FROM "rollup_watermarks"`) {
+			return fakeResult{Columns: []string{"resolution", "through"}, Rows: [][]driver.Value{{"5m", through}}}
+		}
+		return fakeResult{}
+	})
+
+	if err := NewRollupWorker(db, 0).rollup(rollupResolutions[0], nil, rollupNow); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Calls("SELECT DISTINCT")) != 0 {
+		t.Error("listed buckets when already caught up")
+	}
+}
+
+func TestRollupBatchesBuckets(t *testing.T) {
+	var rows [][]driver.Value
+	for i := 0; i < 2*rollupBatch+1; i++ {
+		rows = append(rows, []driver.Value{int64(i), "temperature", int64(0)})
+	}
+	db, fake := newFakeGorm(t, touchedResponder(map[string][][]driver.Value{"5m": rows}))
+
+	if err := NewRollupWorker(db, 0).rollup(rollupResolutions[0], nil, rollupNow); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fake.Calls("INSERT INTO measurement_rollups")); n != 3 {
+		t.Errorf("computed in %d statements, want 3", n)
+	}
+}
+
+func TestRollupErrorKeepsWatermark(t *testing.T) {
+	fail := errors.New("disk full")
+	db, fake := newFakeGorm(t, func(query string, args []driver.Value) fakeResult {
+		if strings.Contains(query, "INSERT INTO measurement_rollups") {
+			return fakeResult{Err: fail}
+		}
+		return touchedResponder(map[string][][]driver.Value{"5m": {{int64(1), "temperature", int64(0)}}})(query, args)
+	})
+
+	if err := NewRollupWorker(db, 0).Run(rollupNow); err == nil {
+		t.Fatal("run succeeded with a failing rollup")
+	}
+	if len(fake.Calls("rollup_watermarks", "UPDATE")) != 0 {
+		t.Error("moved the watermark past buckets that failed")
+	}
+	if indexOf(fake.Queries(), "ROLLBACK") < 0 {
+		t.Error("didn't roll back")
+	}
+	if len(fake.Calls("DELETE")) != 0 {
+		t.Error("purged after a failed rollup")
+	}
+}
+
+func TestRollupCoarserResolutionsReadFinerRollups(t *testing.T) {
+	db, fake := newFakeGorm(t, touchedResponder(map[string][][]driver.Value{
+		"1h": {{int64(1), "temperature", int64(3600)}},
+	}))
+
+	if err := NewRollupWorker(db, 0).Run(rollupNow); err != nil {
+		t.Fatal(err)
+	}
+
+	listed := fake.Calls("SELECT DISTINCT", "FROM measurement_rollups")
+	if len(listed) != 2 || listed[0].Args[0] != "5m" || listed[1].Args[0] != "1h" {
+		t.Fatalf("listed coarser buckets from %v", listed)
+	}
+	computed := fake.Calls("INSERT INTO measurement_rollups")
+	if len(computed) != 1 {
+		t.Fatalf("computed %d times, want 1", len(computed))
+	}
+	args := computed[0].Args
+	if args[3] != "1h" || args[5] != "5m" {
+		t.Errorf("computed %v from %v, want 1h from 5m", args[3], args[5])
+	}
+	if n := len(fake.Calls("rollup_watermarks", "UPDATE")); n != len(rollupResolutions) {
+		t.Errorf("saved %d watermarks, want %d", n, len(rollupResolutions))
+	}
+}
+
+func TestRollupFor(t *testing.T) {
+	cases := []struct {
+		width time.Duration
+		want  string
+	}{
+		{time.Minute, ""},
+		{5 * time.Minute, "5m"},
+		{15 * time.Minute, "5m"},
+		{7 * time.Minute, ""},
+		{time.Hour, "1h"},
+		{6 * time.Hour, "1h"},
+		{24 * time.Hour, "1d"},
+		{7 * 24 * time.Hour, "1d"},
+	}
+	for _, c := range cases {
+		got := ""
+		if r := rollupFor(c.width); r != nil {
+			got = r.Name
+		}
+		if got != c.want {
+			t.Errorf("rollupFor(%v) = %q, want %q", c.width, got, c.want)
+		}
+	}
+}
+
+func indexOf(queries []string, fragment string) int {
+	for i, q := range queries {
+		if strings.Contains(q, fragment) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRollupSkipsBucketsPastSourceRetention(t *testing.T) {
+	old := rollupNow.Add(-3 * 24 * time.Hour).Truncate(5 * time.Minute)
+	recent := rollupNow.Add(-time.Hour).Truncate(5 * time.Minute)
+	db, fake := newFakeGorm(t, touchedResponder(map[string][][]driver.Value{
+		"5m": {
+			{int64(1), "temperature", old.Unix()},
+			{int64(1), "temperature", recent.Unix()},
+			{int64(7), "temperature", old.Unix()},
+			{int64(2), "humidity", old.Unix()},
+		},
+	}))
+	policies := []*RetentionPolicy{
+		{RawDays: 30},
+		{Type: "temperature", RawDays: 2},
+		{DeviceID: 7, Type: "temperature", RawDays: 0},
+	}
+
+	if err := NewRollupWorker(db, 0).rollup(rollupResolutions[0], policies, rollupNow); err != nil {
+		t.Fatal(err)
+	}
+
+	computed := fake.Calls("INSERT INTO measurement_rollups")
+	if len(computed) != 1 {
+		t.Fatalf("computed %d times, want 1", len(computed))
+	}
+	// Device 1's old temperatures are past their two days, device 7 keeps
+	// its temperatures for ever and humidity is kept for thirty days.
+	if devices := computed[0].Args[0]; devices != "{1,7,2}" {
+		t.Errorf("computed buckets for devices %v, want {1,7,2}", devices)
+	}
+	starts := "{" + strconv.FormatInt(recent.Unix(), 10) + "," + strconv.FormatInt(old.Unix(), 10) + "," + strconv.FormatInt(old.Unix(), 10) + "}"
+	if computed[0].Args[2] != starts {
+		t.Errorf("computed buckets starting %v, want %v", computed[0].Args[2], starts)
+	}
+}
+
+func TestPolicyDays(t *testing.T) {
+	p := &RetentionPolicy{RawDays: 1, FiveMinuteDays: 2, HourlyDays: 3, DailyDays: 4}
+	for resolution, want := range map[string]uint{"": 1, "5m": 2, "1h": 3, "1d": 4, "1w": 0} {
+		if got := p.days(resolution); got != want {
+			t.Errorf("days(%q) = %d, want %d", resolution, got, want)
+		}
+	}
+}
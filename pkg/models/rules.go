@@ -0,0 +1,487 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Rule conditions.
+const (
+	ConditionAbove = "gt"
+	ConditionBelow = "lt"
+	ConditionRange = "range"
+	ConditionRate  = "rate"
+)
+
+// AlarmRule raises an alarm when a device's measurements of Type break its
+// condition for at least ForSeconds. A rule is scoped to a device, a group of
+// devices, or every device when neither is set.
+//
+// A range rule is broken by values outside Low to High, a rate rule by a
+// change of more than Threshold per second between consecutive readings.
+type AlarmRule struct {
+	gorm.Model
+	Name       string  `gorm:"not null" json:"name"`
+	DeviceID   uint    `gorm:"index" json:"deviceId"`
+	Group      string  `gorm:"index" json:"group"`
+	Type       string  `gorm:"not null;index" json:"type"`
+	Condition  string  `gorm:"not null" json:"condition"`
+	Threshold  float64 `json:"threshold"`
+	Low        float64 `json:"low"`
+	High       float64 `json:"high"`
+	ForSeconds uint    `json:"forSeconds"`
+	Severity   string  `gorm:"not null" json:"severity"`
+	AlarmType  string  `gorm:"not null" json:"alarmType"`
+	Disabled   bool    `json:"disabled"`
+}
+
+// RuleState is what a rule remembers about a device between readings, the
+// alarm it raised and since when the condition has been broken.
+type RuleState struct {
+	RuleID        uint `gorm:"primary_key;auto_increment:false"`
+	DeviceID      uint `gorm:"primary_key;auto_increment:false"`
+	BreachedSince *time.Time
+	LastValue     float64
+	LastAt        *time.Time
+	AlarmID       *uint
+}
+
+const ruleStateInsert = `
+INSERT INTO rule_states (rule_id, device_id) VALUES (?, ?)
+ON CONFLICT (rule_id, device_id) DO NOTHING`
+
+type ruleGorm struct {
+	db *gorm.DB
+}
+
+type ruleValidator struct {
+	RuleDB
+}
+
+type ruleAuditLogger struct {
+	RuleDB
+}
+
+type ruleAuthorization struct {
+	RuleDB
+}
+
+type ruleValFunc func(*AlarmRule) error
+
+type RuleService interface {
+	RuleDB
+}
+
+type RuleDB interface {
+	ByID(id uint, ctx context.Context) (*AlarmRule, error)
+	Many(ctx context.Context) ([]*AlarmRule, error)
+	Create(rule *AlarmRule, ctx context.Context) error
+	Update(rule *AlarmRule, ctx context.Context) error
+	Delete(id uint, ctx context.Context) error
+}
+
+func NewRuleService(db *gorm.DB) RuleService {
+	return &ruleAuthorization{
+		&ruleAuditLogger{
+			&ruleValidator{
+				&ruleGorm{
+					db: db,
+				},
+			},
+		},
+	}
+}
+
+func (rg *ruleGorm) ByID(id uint, ctx context.Context) (*AlarmRule, error) {
+	var rule AlarmRule
+	if err := rg.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (rg *ruleGorm) Many(ctx context.Context) ([]*AlarmRule, error) {
+	rules := []*AlarmRule{}
+	if err := rg.db.Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (rg *ruleGorm) Create(rule *AlarmRule, ctx context.Context) error {
+	return rg.db.Create(rule).Error
+}
+
+func (rg *ruleGorm) Update(rule *AlarmRule, ctx context.Context) error {
+	return rg.db.Save(rule).Error
+}
+
+// The rule's state goes with it, alarms it raised are left for users to clear.
+func (rg *ruleGorm) Delete(id uint, ctx context.Context) error {
+	rule := AlarmRule{Model: gorm.Model{ID: id}}
+	if err := rg.db.Delete(&rule).Error; err != nil {
+		return err
+	}
+	return rg.db.Where("rule_id = ?", id).Delete(&RuleState{}).Error
+}
+
+func (rv *ruleValidator) Create(rule *AlarmRule, ctx context.Context) error {
+	if err := rv.runRuleValFns(rule, rv.hasName, rv.hasType, rv.oneScope, rv.validCondition, rv.hasSeverity, rv.defaultAlarmType); err != nil {
+		return err
+	}
+	return rv.RuleDB.Create(rule, ctx)
+}
+
+func (rv *ruleValidator) Update(rule *AlarmRule, ctx context.Context) error {
+	if err := rv.runRuleValFns(rule, rv.hasName, rv.hasType, rv.oneScope, rv.validCondition, rv.hasSeverity, rv.defaultAlarmType); err != nil {
+		return err
+	}
+	return rv.RuleDB.Update(rule, ctx)
+}
+
+func (rv *ruleValidator) runRuleValFns(rule *AlarmRule, fns ...ruleValFunc) error {
+	for _, fn := range fns {
+		if err := fn(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rv *ruleValidator) hasName(rule *AlarmRule) error {
+	if rule.Name == "" {
+		return ErrRuleNameRequired
+	}
+	return nil
+}
+
+func (rv *ruleValidator) hasType(rule *AlarmRule) error {
+	if rule.Type == "" {
+		return ErrTypeRequired
+	}
+	return nil
+}
+
+func (rv *ruleValidator) oneScope(rule *AlarmRule) error {
+	if rule.DeviceID != 0 && rule.Group != "" {
+		return ErrRuleScope
+	}
+	return nil
+}
+
+func (rv *ruleValidator) validCondition(rule *AlarmRule) error {
+	switch rule.Condition {
+	case ConditionAbove, ConditionBelow:
+		return nil
+	case ConditionRange:
+		if rule.Low >= rule.High {
+			return ErrInvalidRuleRange
+		}
+		return nil
+	case ConditionRate:
+		if rule.Threshold <= 0 {
+			return ErrInvalidRuleRate
+		}
+		return nil
+	}
+	return ErrInvalidCondition
+}
+
+func (rv *ruleValidator) hasSeverity(rule *AlarmRule) error {
+	if !validSeverity(rule.Severity) {
+		return ErrInvalidSeverity
+	}
+	return nil
+}
+
+func (rv *ruleValidator) defaultAlarmType(rule *AlarmRule) error {
+	if rule.AlarmType == "" {
+		rule.AlarmType = rule.Name
+	}
+	return nil
+}
+
+func (ra *ruleAuditLogger) ByID(id uint, ctx context.Context) (*AlarmRule, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "AlarmRules")
+	return ra.RuleDB.ByID(id, ctx)
+}
+
+func (ra *ruleAuditLogger) Many(ctx context.Context) ([]*AlarmRule, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "AlarmRules")
+	return ra.RuleDB.Many(ctx)
+}
+
+func (ra *ruleAuditLogger) Create(rule *AlarmRule, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogCreate(uc.UserID, "AlarmRules")
+	return ra.RuleDB.Create(rule, ctx)
+}
+
+func (ra *ruleAuditLogger) Update(rule *AlarmRule, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogUpdate(uc.UserID, "AlarmRules")
+	return ra.RuleDB.Update(rule, ctx)
+}
+
+func (ra *ruleAuditLogger) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return ErrNoClaims
+	}
+	LogDelete(uc.UserID, "AlarmRules")
+	return ra.RuleDB.Delete(id, ctx)
+}
+
+// Rules raise alarms, so they share the alarms role.
+func (ra *ruleAuthorization) ByID(id uint, ctx context.Context) (*AlarmRule, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ra.RuleDB.ByID(id, ctx)
+}
+func (ra *ruleAuthorization) Many(ctx context.Context) ([]*AlarmRule, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 1 {
+		return nil, ErrAlarmsReadRequired
+	}
+	return ra.RuleDB.Many(ctx)
+}
+func (ra *ruleAuthorization) Create(rule *AlarmRule, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 2 {
+		return ErrAlarmsWriteRequired
+	}
+	return ra.RuleDB.Create(rule, ctx)
+}
+func (ra *ruleAuthorization) Update(rule *AlarmRule, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 3 {
+		return ErrAlarmsUpdateRequired
+	}
+	return ra.RuleDB.Update(rule, ctx)
+}
+func (ra *ruleAuthorization) Delete(id uint, ctx context.Context) error {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Alarms
+	if err != nil || ar < 4 {
+		return ErrAlarmsDeleteRequired
+	}
+	return ra.RuleDB.Delete(id, ctx)
+}
+
+// RuleEngine checks new measurements against the rules that apply to them,
+// raising alarms through the alarm service and clearing them on recovery.
+type RuleEngine struct {
+	db     *gorm.DB
+	alarms AlarmService
+}
+
+func NewRuleEngine(db *gorm.DB, alarms AlarmService) *RuleEngine {
+	return &RuleEngine{db: db, alarms: alarms}
+}
+
+// Background work acts on behalf of no user, so it carries its own claims
+// with enough access to raise and clear alarms.
+func systemContext() context.Context {
+	claims := &UserClaims{Role: Role{Alarms: 4, Devices: 1, Measurements: 1}}
+	return context.WithValue(context.Background(), userContextKey("User"), claims)
+}
+
+// Evaluate runs every rule matching the measurement's device and type. A nil
+// engine has no rules. A rule that fails doesn't stop the others, the last
+// failure is returned.
+func (re *RuleEngine) Evaluate(measurement *Measurement) error {
+	if re == nil {
+		return nil
+	}
+
+	var rules []*AlarmRule
+	err := re.db.Where("type = ? AND NOT disabled", measurement.Type).
+		Where(`device_id = ? OR (device_id = 0 AND ("group" = '' OR "group" IN (SELECT "group" FROM devices WHERE id = ?)))`,
+			measurement.DeviceID, measurement.DeviceID).
+		Find(&rules).Error
+	if err != nil {
+		return err
+	}
+
+	var failed error
+	for _, rule := range rules {
+		if err := re.apply(rule, measurement); err != nil {
+			log.Printf("Rule %d on device %d: %v", rule.ID, measurement.DeviceID, err)
+			failed = err
+		}
+	}
+	return failed
+}
+
+// Holds the state's row lock while the alarm is raised or cleared, so
+// readings arriving together can't raise the same alarm twice.
+func (re *RuleEngine) apply(rule *AlarmRule, measurement *Measurement) error {
+	tx := re.db.Begin()
+	if err := tx.Exec(ruleStateInsert, rule.ID, measurement.DeviceID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var state RuleState
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").
+		Where("rule_id = ? AND device_id = ?", rule.ID, measurement.DeviceID).
+		First(&state).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Late readings can't change what the rule has already seen.
+	if state.LastAt != nil && measurement.ObservedAt.Before(*state.LastAt) {
+		tx.Rollback()
+		return nil
+	}
+
+	if err := re.step(rule, &state, measurement); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Save(&state).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// step moves the state on by one reading, raising the alarm once the
+// condition has held for the rule's duration and clearing it on recovery.
+func (re *RuleEngine) step(rule *AlarmRule, state *RuleState, measurement *Measurement) error {
+	breached, known := rule.breached(state, measurement)
+	observedAt := measurement.ObservedAt
+	state.LastValue = measurement.Value
+	state.LastAt = &observedAt
+	if !known {
+		return nil
+	}
+
+	if !breached {
+		state.BreachedSince = nil
+		if state.AlarmID == nil {
+			return nil
+		}
+		comment := fmt.Sprintf("Rule %s recovered", rule.Name)
+		if _, err := re.alarms.Transition(*state.AlarmID, AlarmCleared, comment, systemContext()); err != nil &&
+			err != ErrInvalidTransition && !gorm.IsRecordNotFoundError(err) {
+			return err
+		}
+		state.AlarmID = nil
+		return nil
+	}
+
+	if state.BreachedSince == nil {
+		state.BreachedSince = &observedAt
+	}
+	held := observedAt.Sub(*state.BreachedSince)
+	if state.AlarmID != nil || held < time.Duration(rule.ForSeconds)*time.Second {
+		return nil
+	}
+
+	alarm := &Alarm{
+		Type:     rule.AlarmType,
+		Status:   AlarmActive,
+		Severity: rule.Severity,
+		DeviceID: measurement.DeviceID,
+		RuleID:   &rule.ID,
+	}
+	if err := re.alarms.Create(alarm, systemContext()); err != nil {
+		return err
+	}
+	state.AlarmID = &alarm.ID
+	return nil
+}
+
+// breached reports whether the reading breaks the rule. A rate can't be known
+// until there are two readings at different times.
+func (rule *AlarmRule) breached(state *RuleState, measurement *Measurement) (breached, known bool) {
+	value := measurement.Value
+	switch rule.Condition {
+	case ConditionAbove:
+		return value > rule.Threshold, true
+	case ConditionBelow:
+		return value < rule.Threshold, true
+	case ConditionRange:
+		return value < rule.Low || value > rule.High, true
+	case ConditionRate:
+		if state.LastAt == nil {
+			return false, false
+		}
+		elapsed := measurement.ObservedAt.Sub(*state.LastAt).Seconds()
+		if elapsed <= 0 {
+			return false, false
+		}
+		return math.Abs(value-state.LastValue)/elapsed > rule.Threshold, true
+	}
+	return false, false
+}
+
+// measurementRules evaluates the alarm rules against each measurement once it
+// has been stored. A failing rule never fails the measurement, it is logged.
+type measurementRules struct {
+	MeasurementDB
+	engine *RuleEngine
+}
+
+func (mr *measurementRules) Create(measurement *Measurement, ctx context.Context) error {
+	if err := mr.MeasurementDB.Create(measurement, ctx); err != nil {
+		return err
+	}
+	if err := mr.engine.Evaluate(measurement); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+// Readings are evaluated oldest first, so a batch isn't mistaken for late
+// readings.
+func (mr *measurementRules) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	results, err := mr.MeasurementDB.CreateBatch(measurements, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []*Measurement
+	for _, result := range results {
+		if result.Error == "" {
+			created = append(created, measurements[result.Index])
+		}
+	}
+	sort.SliceStable(created, func(i, j int) bool {
+		return created[i].ObservedAt.Before(created[j].ObservedAt)
+	})
+	for _, measurement := range created {
+		if err := mr.engine.Evaluate(measurement); err != nil {
+			log.Println(err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingAlarms records the alarms raised and cleared.
+type recordingAlarms struct {
+	AlarmService
+	raised  []Alarm
+	cleared []uint
+}
+
+func (ra *recordingAlarms) Create(alarm *Alarm, ctx context.Context) error {
+	alarm.ID = uint(len(ra.raised) + 1)
+	ra.raised = append(ra.raised, *alarm)
+	return nil
+}
+
+func (ra *recordingAlarms) Transition(id uint, status, comment string, ctx context.Context) (*Alarm, error) {
+	ra.cleared = append(ra.cleared, id)
+	return &Alarm{}, nil
+}
+
+func reading(value float64, seconds int) *Measurement {
+	return &Measurement{DeviceID: 1, Type: "temperature", Value: value, ObservedAt: rollupNow.Add(time.Duration(seconds) * time.Second)}
+}
+
+func TestRuleBreached(t *testing.T) {
+	last := rollupNow
+	seen := &RuleState{LastValue: 10, LastAt: &last}
+
+	cases := []struct {
+		name            string
+		rule            AlarmRule
+		state           *RuleState
+		reading         *Measurement
+		breached, known bool
+	}{
+		{"above", AlarmRule{Condition: ConditionAbove, Threshold: 30}, &RuleState{}, reading(31, 0), true, true},
+		{"above at threshold", AlarmRule{Condition: ConditionAbove, Threshold: 30}, &RuleState{}, reading(30, 0), false, true},
+		{"below", AlarmRule{Condition: ConditionBelow, Threshold: 5}, &RuleState{}, reading(4, 0), true, true},
+		{"below at threshold", AlarmRule{Condition: ConditionBelow, Threshold: 5}, &RuleState{}, reading(5, 0), false, true},
+		{"under range", AlarmRule{Condition: ConditionRange, Low: 5, High: 30}, &RuleState{}, reading(4, 0), true, true},
+		{"over range", AlarmRule{Condition: ConditionRange, Low: 5, High: 30}, &RuleState{}, reading(31, 0), true, true},
+		{"in range", AlarmRule{Condition: ConditionRange, Low: 5, High: 30}, &RuleState{}, reading(30, 0), false, true},
+		{"rate first reading", AlarmRule{Condition: ConditionRate, Threshold: 1}, &RuleState{}, reading(100, 0), false, false},
+		{"rate no time elapsed", AlarmRule{Condition: ConditionRate, Threshold: 1}, seen, reading(100, 0), false, false},
+		{"rate rising", AlarmRule{Condition: ConditionRate, Threshold: 1}, seen, reading(31, 10), true, true},
+		{"rate falling", AlarmRule{Condition: ConditionRate, Threshold: 1}, seen, reading(-1, 10), true, true},
+		{"rate at threshold", AlarmRule{Condition: ConditionRate, Threshold: 1}, seen, reading(20, 10), false, true},
+		{"unknown condition", AlarmRule{Condition: "eq"}, &RuleState{}, reading(1, 0), false, false},
+	}
+	for _, c := range cases {
+		breached, known := c.rule.breached(c.state, c.reading)
+		if breached != c.breached || known != c.known {
+			t.Errorf("%s: breached %v known %v, want %v and %v", c.name, breached, known, c.breached, c.known)
+		}
+	}
+}
+
+func TestRuleEngineStep(t *testing.T) {
+	cases := []struct {
+		name     string
+		rule     AlarmRule
+		readings []*Measurement
+		// The alarms raised and cleared after each reading.
+		raised, cleared []int
+	}{
+		{
+			name:     "raises at once",
+			rule:     AlarmRule{Condition: ConditionAbove, Threshold: 30},
+			readings: []*Measurement{reading(20, 0), reading(31, 10), reading(35, 20)},
+			raised:   []int{0, 1, 1},
+			cleared:  []int{0, 0, 0},
+		},
+		{
+			name:     "clears on recovery",
+			rule:     AlarmRule{Condition: ConditionAbove, Threshold: 30},
+			readings: []*Measurement{reading(31, 0), reading(29, 10), reading(31, 20)},
+			raised:   []int{1, 1, 2},
+			cleared:  []int{0, 1, 1},
+		},
+		{
+			name:     "holds for the duration",
+			rule:     AlarmRule{Condition: ConditionAbove, Threshold: 30, ForSeconds: 60},
+			readings: []*Measurement{reading(31, 0), reading(31, 30), reading(31, 59), reading(31, 60), reading(31, 90)},
+			raised:   []int{0, 0, 0, 1, 1},
+			cleared:  []int{0, 0, 0, 0, 0},
+		},
+		{
+			name:     "recovery restarts the hold",
+			rule:     AlarmRule{Condition: ConditionAbove, Threshold: 30, ForSeconds: 60},
+			readings: []*Measurement{reading(31, 0), reading(29, 50), reading(31, 70), reading(31, 120), reading(31, 130)},
+			raised:   []int{0, 0, 0, 0, 1},
+			cleared:  []int{0, 0, 0, 0, 0},
+		},
+		{
+			name:     "rate from the second reading",
+			rule:     AlarmRule{Condition: ConditionRate, Threshold: 1},
+			readings: []*Measurement{reading(0, 0), reading(5, 10), reading(25, 20), reading(26, 30)},
+			raised:   []int{0, 0, 1, 1},
+			cleared:  []int{0, 0, 0, 1},
+		},
+	}
+	for _, c := range cases {
+		alarms := &recordingAlarms{}
+		re := &RuleEngine{alarms: alarms}
+		rule := c.rule
+		rule.ID = 3
+		rule.AlarmType = "overheat"
+		rule.Severity = "MAJOR"
+
+		state := &RuleState{RuleID: 3, DeviceID: 1}
+		for i, m := range c.readings {
+			if err := re.step(&rule, state, m); err != nil {
+				t.Fatalf("%s: reading %d: %v", c.name, i, err)
+			}
+			if len(alarms.raised) != c.raised[i] || len(alarms.cleared) != c.cleared[i] {
+				t.Errorf("%s: after reading %d raised %d and cleared %d, want %d and %d",
+					c.name, i, len(alarms.raised), len(alarms.cleared), c.raised[i], c.cleared[i])
+			}
+			if !state.LastAt.Equal(m.ObservedAt) || state.LastValue != m.Value {
+				t.Errorf("%s: reading %d not remembered: %+v", c.name, i, state)
+			}
+		}
+
+		for _, alarm := range alarms.raised {
+			if alarm.Type != "overheat" || alarm.Severity != "MAJOR" || alarm.DeviceID != 1 || *alarm.RuleID != 3 {
+				t.Errorf("%s: raised %+v", c.name, alarm)
+			}
+		}
+		// The alarm is forgotten once cleared, and cleared only once.
+		if len(alarms.cleared) > 0 && alarms.cleared[0] != 1 {
+			t.Errorf("%s: cleared %v, want alarm 1", c.name, alarms.cleared)
+		}
+	}
+}
+
+var ruleStateColumns = []string{"rule_id", "device_id", "breached_since", "last_value", "last_at", "alarm_id"}
+
+// ruleResponder answers with the rules given, and with a rule state last
+// updated at lastAt. Inserting state for a rule in fail errors.
+func ruleResponder(rules []int64, lastAt time.Time, fail map[int64]bool) func(string, []driver.Value) fakeResult {
+	return func(query string, args []driver.Value) fakeResult {
+		switch {
+		case strings.Contains(query, `FROM "alarm_rules"`):
+			var rows [][]driver.Value
+			for _, id := range rules {
+				rows = append(rows, []driver.Value{id, "temperature", ConditionAbove, 30.0, "overheat", "MAJOR"})
+			}
+			return fakeResult{Columns: []string{"id", "type", "condition", "threshold", "alarm_type", "severity"}, Rows: rows}
+		case strings.Contains(query, "INSERT INTO rule_states") && fail[args[0].(int64)]:
+			return fakeResult{Err: errors.New("deadlock detected")}
+		case strings.Contains(query, `FROM "rule_states"`):
+			return fakeResult{Columns: ruleStateColumns, Rows: [][]driver.Value{{args[0], args[1], nil, 20.0, lastAt, nil}}}
+		}
+		return fakeResult{}
+	}
+}
+
+func TestRuleEngineIgnoresLateReadings(t *testing.T) {
+	db, fake := newFakeGorm(t, ruleResponder([]int64{3}, rollupNow, nil))
+	alarms := &recordingAlarms{}
+	re := NewRuleEngine(db, alarms)
+
+	if err := re.Evaluate(reading(50, -10)); err != nil {
+		t.Fatal(err)
+	}
+	if len(alarms.raised) != 0 || len(fake.Calls(`UPDATE "rule_states"`)) != 0 {
+		t.Errorf("late reading raised %d alarms after %q", len(alarms.raised), fake.Queries())
+	}
+	if queries := fake.Queries(); queries[len(queries)-1] != "ROLLBACK" {
+		t.Errorf("late reading ended with %q, want ROLLBACK", queries[len(queries)-1])
+	}
+
+	if err := re.Evaluate(reading(50, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if len(alarms.raised) != 1 {
+		t.Errorf("newer reading raised %d alarms, want 1", len(alarms.raised))
+	}
+}
+
+func TestRuleEngineContinuesPastFailures(t *testing.T) {
+	db, _ := newFakeGorm(t, ruleResponder([]int64{3, 4, 5}, rollupNow, map[int64]bool{4: true}))
+	alarms := &recordingAlarms{}
+	re := NewRuleEngine(db, alarms)
+
+	err := re.Evaluate(reading(50, 10))
+	if err == nil || err.Error() != "deadlock detected" {
+		t.Errorf("error %v, want the failing rule's", err)
+	}
+	var rules []string
+	for _, alarm := range alarms.raised {
+		rules = append(rules, fmt.Sprint(*alarm.RuleID))
+	}
+	if strings.Join(rules, ",") != "3,5" {
+		t.Errorf("raised alarms for rules %v, want 3 and 5", rules)
+	}
+}
@@ -1,20 +1,36 @@
 package models
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/naspinall/Hive/pkg/notify"
 )
 
 type ServicesConfig func(*Services) error
 
 type Services struct {
-	Alarm        AlarmService
-	Device       DeviceService
-	Measurement  MeasurementService
-	User         UserService
-	Subscription SubscriptionService
-	RBAC         RBACService
-	db           *gorm.DB
+	Alarm         AlarmService
+	Device        DeviceService
+	Measurement   MeasurementService
+	User          UserService
+	Subscription  SubscriptionService
+	RBAC          RBACService
+	Retention     RetentionService
+	Rollup        *RollupWorker
+	Events        EventService
+	Rules         RuleService
+	Heartbeat     *HeartbeatMonitor
+	Notifications NotificationService
+	Escalations   EscalationService
+	Escalator     *Escalator
+	Maintenance   MaintenanceService
+	History       HistoryService
+	hub           *EventHub
+	engine        *RuleEngine
+	notifier      *Notifier
+	db            *gorm.DB
 }
 
 func NewServices(cfgs ...ServicesConfig) (*Services, error) {
@@ -28,11 +44,14 @@ func NewServices(cfgs ...ServicesConfig) (*Services, error) {
 }
 
 func (s *Services) AutoMigrate() error {
-	return s.db.AutoMigrate(&User{}, &Alarm{}, &Measurement{}, &Device{}, &Subscription{}, &Role{}).Error
+	if err := s.db.AutoMigrate(&User{}, &Alarm{}, &Measurement{}, &Device{}, &Subscription{}, &Role{}, &RetentionPolicy{}, &MeasurementRollup{}, &RollupWatermark{}, &IdempotencyKey{}, &MeasurementState{}, &AlarmRule{}, &RuleState{}, &NotificationChannel{}, &NotificationRoute{}, &EscalationPolicy{}, &EscalationStep{}, &Escalation{}, &MaintenanceWindow{}, &AlarmComment{}, &AlarmEvent{}).Error; err != nil {
+		return err
+	}
+	return s.db.Exec(alarmOpenIndex).Error
 }
 
 func (s *Services) DestructiveReset() error {
-	if err := s.db.DropTable(&User{}, &Alarm{}, &Measurement{}, &Device{}, &Subscription{}, &Role{}).Error; err != nil {
+	if err := s.db.DropTable(&User{}, &Alarm{}, &Measurement{}, &Device{}, &Subscription{}, &Role{}, &RetentionPolicy{}, &MeasurementRollup{}, &RollupWatermark{}, &IdempotencyKey{}, &MeasurementState{}, &AlarmRule{}, &RuleState{}, &NotificationChannel{}, &NotificationRoute{}, &EscalationPolicy{}, &EscalationStep{}, &Escalation{}, &MaintenanceWindow{}, &AlarmComment{}, &AlarmEvent{}).Error; err != nil {
 		return err
 	}
 	return s.AutoMigrate()
@@ -61,15 +80,37 @@ func WithLogMode(mode bool) ServicesConfig {
 	}
 }
 
-func WithAlarms() ServicesConfig {
+func WithAlarms(idempotencyWindow time.Duration) ServicesConfig {
+	return func(s *Services) error {
+		s.Alarm = NewAlarmService(s.db, s.Subscription, s.hub, s.notifier, s.Escalator, idempotencyWindow)
+		return nil
+	}
+}
+
+// WithRules must come after WithAlarms, which raises its alarms, and before
+// WithMeasurements, whose readings it evaluates.
+func WithRules() ServicesConfig {
+	return func(s *Services) error {
+		s.Rules = NewRuleService(s.db)
+		s.engine = NewRuleEngine(s.db, s.Alarm)
+		return nil
+	}
+}
+
+// WithHeartbeat must come after WithAlarms and WithRules, and before
+// WithMeasurements. Only alarms raised through the alarm service from here on
+// count as hearing from the device.
+func WithHeartbeat(interval, check time.Duration) ServicesConfig {
 	return func(s *Services) error {
-		s.Alarm = NewAlarmService(s.db, s.Subscription)
+		s.Heartbeat = NewHeartbeatMonitor(s.db, s.Alarm, interval, check)
+		s.Alarm = &alarmHeartbeat{AlarmDB: s.Alarm, heartbeat: s.Heartbeat}
 		return nil
 	}
 }
-func WithMeasurements() ServicesConfig {
+
+func WithMeasurements(futureTolerance, idempotencyWindow time.Duration) ServicesConfig {
 	return func(s *Services) error {
-		s.Measurement = NewMeasurementService(s.db, s.Subscription)
+		s.Measurement = NewMeasurementService(s.db, s.Subscription, s.hub, s.engine, s.Heartbeat, futureTolerance, idempotencyWindow)
 		return nil
 	}
 }
@@ -93,9 +134,61 @@ func WithSubscriptions() ServicesConfig {
 	}
 }
 
+// WithEvents must come before WithMeasurements and WithAlarms, so their
+// changes are published to the hub.
+func WithEvents(buffer int) ServicesConfig {
+	return func(s *Services) error {
+		s.hub = NewEventHub(buffer)
+		s.Events = NewEventService(s.hub)
+		return nil
+	}
+}
+
+// WithNotifications must come before WithAlarms. Senders are keyed by the
+// channel kind they deliver.
+func WithNotifications(senders map[string]notify.Sender) ServicesConfig {
+	return func(s *Services) error {
+		s.Notifications = NewNotificationService(s.db)
+		s.notifier = NewNotifier(s.db, senders)
+		return nil
+	}
+}
+
+// WithEscalations must come after WithNotifications, which sends each step,
+// and before WithAlarms.
+func WithEscalations(interval time.Duration) ServicesConfig {
+	return func(s *Services) error {
+		s.Escalations = NewEscalationService(s.db)
+		s.Escalator = NewEscalator(s.db, s.notifier, interval)
+		return nil
+	}
+}
+
+func WithMaintenance() ServicesConfig {
+	return func(s *Services) error {
+		s.Maintenance = NewMaintenanceService(s.db)
+		return nil
+	}
+}
+
+func WithHistory() ServicesConfig {
+	return func(s *Services) error {
+		s.History = NewHistoryService(s.db)
+		return nil
+	}
+}
+
 func WithRBAC() ServicesConfig {
 	return func(s *Services) error {
 		s.RBAC = NewRBACService(s.db)
 		return nil
 	}
 }
+
+func WithRetention(interval time.Duration) ServicesConfig {
+	return func(s *Services) error {
+		s.Retention = NewRetentionService(s.db)
+		s.Rollup = NewRollupWorker(s.db, interval)
+		return nil
+	}
+}
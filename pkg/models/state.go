@@ -0,0 +1,137 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// MeasurementState is the most recently observed measurement of a type for a
+// device.
+type MeasurementState struct {
+	DeviceID      uint      `gorm:"primary_key;auto_increment:false" json:"deviceId"`
+	Type          string    `gorm:"primary_key" json:"type"`
+	MeasurementID uint      `json:"measurementId"`
+	Value         float64   `json:"value"`
+	Unit          string    `json:"unit"`
+	ObservedAt    time.Time `json:"observedAt"`
+}
+
+// Only replaces the current state when the measurement is at least as recent,
+// so late readings don't overwrite newer ones.
+const stateUpsert = `
+INSERT INTO measurement_states (device_id, type, measurement_id, value, unit, observed_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (device_id, type) DO UPDATE SET
+	measurement_id = EXCLUDED.measurement_id, value = EXCLUDED.value,
+	unit = EXCLUDED.unit, observed_at = EXCLUDED.observed_at
+WHERE measurement_states.observed_at <= EXCLUDED.observed_at`
+
+const stateRecompute = `
+INSERT INTO measurement_states (device_id, type, measurement_id, value, unit, observed_at)
+SELECT device_id, type, id, value, unit, observed_at
+FROM measurements
+WHERE deleted_at IS NULL AND device_id = ? AND type = ?
+ORDER BY observed_at DESC, id DESC
+LIMIT 1`
+
+// measurementState keeps the measurement_states table in step with every
+// change to a device's measurements.
+type measurementState struct {
+	MeasurementDB
+	db *gorm.DB
+}
+
+func (mg *measurementGorm) State(ids []uint, ctx context.Context) ([]MeasurementState, error) {
+	states := []MeasurementState{}
+	if err := mg.db.Where("device_id IN (?)", ids).Order("device_id, type").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (ms *measurementState) Create(measurement *Measurement, ctx context.Context) error {
+	if err := ms.MeasurementDB.Create(measurement, ctx); err != nil {
+		return err
+	}
+	return ms.apply(measurement)
+}
+
+func (ms *measurementState) CreateBatch(measurements []*Measurement, ctx context.Context) ([]BatchResult, error) {
+	results, err := ms.MeasurementDB.CreateBatch(measurements, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+		if err := ms.apply(measurements[result.Index]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// The update may move the measurement to another type or time, so both the
+// old and new states are rebuilt from the measurements table.
+func (ms *measurementState) Update(measurement *Measurement, ctx context.Context) error {
+	previous, err := ms.MeasurementDB.ByID(measurement.ID, ctx)
+	if err != nil {
+		return err
+	}
+	if err := ms.MeasurementDB.Update(measurement, ctx); err != nil {
+		return err
+	}
+	if err := ms.recompute(previous.DeviceID, previous.Type); err != nil {
+		return err
+	}
+	return ms.recompute(measurement.DeviceID, measurement.Type)
+}
+
+func (ms *measurementState) Delete(id uint, ctx context.Context) error {
+	measurement, err := ms.MeasurementDB.ByID(id, ctx)
+	if err != nil {
+		return err
+	}
+	if err := ms.MeasurementDB.Delete(id, ctx); err != nil {
+		return err
+	}
+	return ms.recompute(measurement.DeviceID, measurement.Type)
+}
+
+func (ms *measurementState) apply(m *Measurement) error {
+	return ms.db.Exec(stateUpsert, m.DeviceID, m.Type, m.ID, m.Value, m.Unit, m.ObservedAt).Error
+}
+
+func (ms *measurementState) recompute(deviceID uint, measurementType string) error {
+	tx := ms.db.Begin()
+	if err := tx.Where("device_id = ? AND type = ?", deviceID, measurementType).Delete(&MeasurementState{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Exec(stateRecompute, deviceID, measurementType).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (ma *measurementAuditLogger) State(ids []uint, ctx context.Context) ([]MeasurementState, error) {
+	uc, err := ExtractUserClaims(ctx)
+	if err != nil {
+		return nil, ErrNoClaims
+	}
+	LogGet(uc.UserID, "Measurements")
+	return ma.MeasurementDB.State(ids, ctx)
+}
+
+func (ma *measurementAuthorization) State(ids []uint, ctx context.Context) ([]MeasurementState, error) {
+	uc, err := ExtractUserClaims(ctx)
+	ar := uc.Role.Measurements
+	if err != nil || ar < 1 {
+		return nil, ErrMeasurementReadRequired
+	}
+	return ma.MeasurementDB.State(ids, ctx)
+}
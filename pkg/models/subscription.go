@@ -26,6 +26,20 @@ type SubscriptionMessage struct {
 	Payload  interface{} `json:"payload"`
 }
 
+// SubscriptionQuery filters a page of subscriptions, empty fields match every
+// subscription.
+type SubscriptionQuery struct {
+	PageQuery
+	DeviceID uint
+	Type     string
+	Action   string
+}
+
+type SubscriptionPage struct {
+	Subscriptions []*Subscription `json:"subscriptions"`
+	Next          string          `json:"next,omitempty"`
+}
+
 type subscriptionGorm struct {
 	db *gorm.DB
 }
@@ -45,6 +59,7 @@ type SubscriptionDB interface {
 	Update(subscription *Subscription, ctx context.Context) error
 	Delete(id uint, ctx context.Context) error
 	Many(ctx context.Context) ([]*Subscription, error)
+	Query(query *SubscriptionQuery, ctx context.Context) (*SubscriptionPage, error)
 	Webhook(deviceID uint, action, Type string, data interface{}) error
 }
 
@@ -86,6 +101,35 @@ func (sg *subscriptionGorm) Many(ctx context.Context) ([]*Subscription, error) {
 	return subscriptions, nil
 }
 
+func (sg *subscriptionGorm) Query(query *SubscriptionQuery, ctx context.Context) (*SubscriptionPage, error) {
+	db := sg.db
+	if query.DeviceID != 0 {
+		db = db.Where("device_id = ?", query.DeviceID)
+	}
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	db, err := query.scope(db)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := []*Subscription{}
+	if err := db.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	page := &SubscriptionPage{Subscriptions: subscriptions}
+	if n, more := query.more(len(subscriptions)); more {
+		page.Subscriptions = subscriptions[:n]
+		page.Next = encodeIDCursor(subscriptions[n-1].ID)
+	}
+	return page, nil
+}
+
 func (sg *subscriptionGorm) Create(subscription *Subscription, ctx context.Context) error {
 	return sg.db.Create(subscription).Error
 }
@@ -155,7 +199,6 @@ func (sa subscriptionAuthorization) Create(subscription *Subscription, ctx conte
 	return sa.SubscriptionDB.Create(subscription, ctx)
 }
 func (sa subscriptionAuthorization) Update(subscription *Subscription, ctx context.Context) error {
-	return sa.SubscriptionDB.Update(subscription, ctx)
 	uc, err := ExtractUserClaims(ctx)
 	sr := uc.Role.Subscriptions
 	if err != nil || sr < 3 {
@@ -179,3 +222,11 @@ func (sa subscriptionAuthorization) Many(ctx context.Context) ([]*Subscription,
 	}
 	return sa.SubscriptionDB.Many(ctx)
 }
+func (sa subscriptionAuthorization) Query(query *SubscriptionQuery, ctx context.Context) (*SubscriptionPage, error) {
+	uc, err := ExtractUserClaims(ctx)
+	sr := uc.Role.Subscriptions
+	if err != nil || sr < 1 {
+		return nil, ErrSubscriptionsReadRequired
+	}
+	return sa.SubscriptionDB.Query(query, ctx)
+}
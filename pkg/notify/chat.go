@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// ChatSender posts to Slack and Microsoft Teams incoming webhooks, both
+// accept a JSON object with the message in its text field.
+type ChatSender struct {
+	Client *http.Client
+}
+
+type chatMessage struct {
+	Text string `json:"text"`
+}
+
+func NewChatSender(client *http.Client) *ChatSender {
+	return &ChatSender{Client: defaultClient(client)}
+}
+
+func (cs *ChatSender) Send(target string, msg *Message) error {
+	text := msg.Body
+	if msg.Subject != "" {
+		text = msg.Subject + "\n" + msg.Body
+	}
+
+	b, err := json.Marshal(&chatMessage{Text: text})
+	if err != nil {
+		return err
+	}
+	return post(cs.Client, target, "application/json", bytes.NewReader(b))
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailSender sends plain text email through an SMTP server. Auth may be nil
+// for servers that don't require it.
+type EmailSender struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// NewEmailSender authenticates with PLAIN auth when a username is given.
+func NewEmailSender(host string, port int, from, username, password string) *EmailSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailSender{
+		Addr: fmt.Sprintf("%s:%d", host, port),
+		From: from,
+		Auth: auth,
+	}
+}
+
+// Send mails the message to every address in the comma separated target.
+func (es *EmailSender) Send(target string, msg *Message) error {
+	to, err := mail.ParseAddressList(target)
+	if err != nil {
+		return err
+	}
+
+	recipients := make([]string, 0, len(to))
+	headers := make([]string, 0, len(to))
+	for _, address := range to {
+		recipients = append(recipients, address.Address)
+		headers = append(headers, address.String())
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", es.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(headers, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	body := strings.Replace(msg.Body, "\r\n", "\n", -1)
+	b.WriteString(strings.Replace(body, "\n", "\r\n", -1))
+
+	return smtp.SendMail(es.Addr, es.Auth, es.From, recipients, b.Bytes())
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// smtpSession is what a stub SMTP server was told over one connection.
+type smtpSession struct {
+	Auth string
+	From string
+	To   []string
+	Data string
+}
+
+// serveSMTP accepts a single connection and speaks just enough SMTP for
+// net/smtp, advertising PLAIN auth. Recipients in reject are refused.
+func serveSMTP(t *testing.T, reject map[string]bool) (host string, port int, done <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var session smtpSession
+		defer func() { sessions <- session }()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 stub ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO":
+				reply("250-stub")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				session.Auth = line
+				reply("235 authenticated")
+			case "MAIL":
+				session.From = line
+				reply("250 ok")
+			case "RCPT":
+				if reject[line] {
+					reply("550 no such user")
+					continue
+				}
+				session.To = append(session.To, line)
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				session.Data = data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, sessions
+}
+
+func TestEmailSend(t *testing.T) {
+	host, port, done := serveSMTP(t, nil)
+	es := NewEmailSender(host, port, "hive@example.com", "hive", "secret")
+
+	msg := &Message{Subject: "Température high", Body: "Alarm raised\non boiler"}
+	if err := es.Send("Ops <ops@example.com>, oncall@example.com", msg); err != nil {
+		t.Fatal(err)
+	}
+	session := <-done
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("\x00hive\x00secret"))
+	if session.Auth != "AUTH PLAIN "+credentials {
+		t.Errorf("authenticated with %q", session.Auth)
+	}
+	if session.From != "MAIL FROM:<hive@example.com>" {
+		t.Errorf("mail from %q", session.From)
+	}
+	want := []string{"RCPT TO:<ops@example.com>", "RCPT TO:<oncall@example.com>"}
+	if strings.Join(session.To, ",") != strings.Join(want, ",") {
+		t.Errorf("recipients %q, want %q", session.To, want)
+	}
+
+	for _, header := range []string{
+		"From: hive@example.com\r\n",
+		"To: \"Ops\" <ops@example.com>, <oncall@example.com>\r\n",
+		"Subject: =?utf-8?q?Temp=C3=A9rature_high?=\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+	} {
+		if !strings.Contains(session.Data, header) {
+			t.Errorf("message is missing %q:\n%s", header, session.Data)
+		}
+	}
+	// Bare newlines in the body go out as CRLF.
+	if !strings.HasSuffix(session.Data, "\r\n\r\nAlarm raised\r\non boiler\r\n") {
+		t.Errorf("message body:\n%q", session.Data)
+	}
+}
+
+func TestEmailSendErrors(t *testing.T) {
+	host, port, done := serveSMTP(t, map[string]bool{"RCPT TO:<nobody@example.com>": true})
+	es := &EmailSender{Addr: net.JoinHostPort(host, strconv.Itoa(port)), From: "hive@example.com"}
+
+	if err := es.Send("nobody@example.com", &Message{Body: "hi"}); err == nil {
+		t.Error("refused recipient: sent, want an error")
+	}
+	if session := <-done; session.Data != "" {
+		t.Errorf("refused recipient: sent data %q", session.Data)
+	}
+
+	if err := es.Send("not an address", &Message{Body: "hi"}); err == nil {
+		t.Error("bad target: sent, want an error")
+	}
+}
@@ -0,0 +1,65 @@
+// Package notify delivers human readable notifications over email and HTTP.
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Message is a rendered notification. Subject is only used by email.
+type Message struct {
+	Subject     string
+	Body        string
+	ContentType string
+}
+
+// Sender delivers a message to a target, an email address list for email and
+// a URL for everything else.
+type Sender interface {
+	Send(target string, msg *Message) error
+}
+
+const DefaultTimeout = 10 * time.Second
+
+func defaultClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	return client
+}
+
+// post sends body to url, any status other than 2xx is an error.
+func post(client *http.Client, url, contentType string, body io.Reader) error {
+	resp, err := client.Post(url, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notify: %s responded %s", url, resp.Status)
+	}
+	return nil
+}
+
+// HTTPSender posts the message body as is, with its content type.
+type HTTPSender struct {
+	Client *http.Client
+}
+
+func NewHTTPSender(client *http.Client) *HTTPSender {
+	return &HTTPSender{Client: defaultClient(client)}
+}
+
+func (hs *HTTPSender) Send(target string, msg *Message) error {
+	contentType := msg.ContentType
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	return post(hs.Client, target, contentType, bytes.NewBufferString(msg.Body))
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// request is what a stub webhook received.
+type request struct {
+	Method      string
+	ContentType string
+	Body        string
+}
+
+// serveWebhook answers every request with status, and records them.
+func serveWebhook(t *testing.T, status int) (*httptest.Server, *[]request) {
+	t.Helper()
+	var received []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = append(received, request{Method: r.Method, ContentType: r.Header.Get("Content-Type"), Body: string(b)})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &received
+}
+
+func TestHTTPSend(t *testing.T) {
+	cases := []struct {
+		name        string
+		msg         Message
+		contentType string
+	}{
+		{"plain text", Message{Subject: "ignored", Body: "alarm raised"}, "text/plain; charset=utf-8"},
+		{"own content type", Message{Body: `{"alarm":9}`, ContentType: "application/json"}, "application/json"},
+	}
+	for _, c := range cases {
+		srv, received := serveWebhook(t, http.StatusNoContent)
+		if err := NewHTTPSender(srv.Client()).Send(srv.URL, &c.msg); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		want := request{Method: http.MethodPost, ContentType: c.contentType, Body: c.msg.Body}
+		if len(*received) != 1 || (*received)[0] != want {
+			t.Errorf("%s: received %+v, want %+v", c.name, *received, want)
+		}
+	}
+}
+
+func TestChatSend(t *testing.T) {
+	cases := []struct {
+		name string
+		msg  Message
+		text string
+	}{
+		{"subject and body", Message{Subject: "[critical] temperature", Body: "on boiler"}, "[critical] temperature\non boiler"},
+		{"body only", Message{Body: "on boiler"}, "on boiler"},
+	}
+	for _, c := range cases {
+		srv, received := serveWebhook(t, http.StatusOK)
+		if err := NewChatSender(srv.Client()).Send(srv.URL, &c.msg); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(*received) != 1 || (*received)[0].ContentType != "application/json" {
+			t.Fatalf("%s: received %+v", c.name, *received)
+		}
+		var posted chatMessage
+		if err := json.Unmarshal([]byte((*received)[0].Body), &posted); err != nil {
+			t.Fatal(err)
+		}
+		if posted.Text != c.text {
+			t.Errorf("%s: posted %q, want %q", c.name, posted.Text, c.text)
+		}
+	}
+}
+
+func TestSendRejected(t *testing.T) {
+	for _, status := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusInternalServerError} {
+		srv, _ := serveWebhook(t, status)
+		for name, sender := range map[string]Sender{"http": NewHTTPSender(srv.Client()), "chat": NewChatSender(srv.Client())} {
+			if err := sender.Send(srv.URL, &Message{Body: "alarm"}); err == nil {
+				t.Errorf("%s answered %d: sent, want an error", name, status)
+			}
+		}
+	}
+
+	srv, _ := serveWebhook(t, http.StatusOK)
+	srv.Close()
+	if err := NewHTTPSender(srv.Client()).Send(srv.URL, &Message{Body: "alarm"}); err == nil {
+		t.Error("closed server: sent, want an error")
+	}
+}
+
+func TestDefaultClient(t *testing.T) {
+	if client := NewHTTPSender(nil).Client; client == nil || client.Timeout != DefaultTimeout {
+		t.Errorf("default client %+v, want a %v timeout", client, DefaultTimeout)
+	}
+	client := &http.Client{}
+	if NewChatSender(client).Client != client {
+		t.Error("client given was replaced")
+	}
+}
@@ -0,0 +1,160 @@
+// Package remotewrite decodes Prometheus remote write requests, a snappy
+// compressed prometheus.WriteRequest protobuf.
+package remotewrite
+
+import (
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var ErrInvalidRequest = errors.New("invalid write request")
+
+// MetricNameLabel holds the name of a series' metric.
+const MetricNameLabel = "__name__"
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value, Timestamp is in milliseconds since the Unix epoch.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Label returns the value of the named label, or an empty string.
+func (ts *TimeSeries) Label(name string) string {
+	for _, l := range ts.Labels {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+type WriteRequest struct {
+	Timeseries []TimeSeries
+}
+
+// Decode decompresses and parses the body of a remote write request. Fields
+// other than the series labels and samples, such as metadata, are skipped.
+func Decode(body []byte) (*WriteRequest, error) {
+	b, err := decodeSnappy(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &WriteRequest{}
+	err = walk(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		ts, err := decodeTimeSeries(v)
+		if err != nil {
+			return err
+		}
+		req.Timeseries = append(req.Timeseries, *ts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeTimeSeries(b []byte) (*TimeSeries, error) {
+	ts := &TimeSeries{}
+	err := walk(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			l, err := decodeLabel(v)
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, *l)
+		case 2:
+			s, err := decodeSample(v)
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, *s)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+func decodeLabel(b []byte) (*Label, error) {
+	l := &Label{}
+	err := walk(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			l.Name = string(v)
+		case 2:
+			l.Value = string(v)
+		}
+		return nil
+	})
+	return l, err
+}
+
+func decodeSample(b []byte) (*Sample, error) {
+	s := &Sample{}
+	err := walk(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			bits, _ := protowire.ConsumeFixed64(v)
+			s.Value = math.Float64frombits(bits)
+		case num == 2 && typ == protowire.VarintType:
+			ts, _ := protowire.ConsumeVarint(v)
+			s.Timestamp = int64(ts)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// walk calls fn with each field of a message. Length delimited values are
+// passed without their length, other values are passed in their wire form.
+func walk(b []byte, fn func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ErrInvalidRequest
+		}
+		b = b[n:]
+
+		var v []byte
+		if typ == protowire.BytesType {
+			v, n = protowire.ConsumeBytes(b)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n >= 0 {
+				v = b[:n]
+			}
+		}
+		if n < 0 {
+			return ErrInvalidRequest
+		}
+		b = b[n:]
+
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package remotewrite
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func message(fields ...[]byte) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func bytesField(num protowire.Number, v []byte) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func label(name, value string) []byte {
+	return bytesField(1, message(bytesField(1, []byte(name)), bytesField(2, []byte(value))))
+}
+
+func sample(value float64, timestamp int64) []byte {
+	b := protowire.AppendTag(nil, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(value))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(timestamp))
+	return bytesField(2, b)
+}
+
+func series(fields ...[]byte) []byte {
+	return bytesField(1, message(fields...))
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name string
+		body []byte
+		want *WriteRequest
+	}{
+		{"empty", nil, &WriteRequest{}},
+		{
+			name: "series",
+			body: message(
+				series(label(MetricNameLabel, "temperature"), label("hive_device", "boiler"), sample(21.5, 1000), sample(-3, -1)),
+				series(label(MetricNameLabel, "up"), sample(1, 2000)),
+			),
+			want: &WriteRequest{Timeseries: []TimeSeries{
+				{
+					Labels:  []Label{{MetricNameLabel, "temperature"}, {"hive_device", "boiler"}},
+					Samples: []Sample{{21.5, 1000}, {-3, -1}},
+				},
+				{Labels: []Label{{MetricNameLabel, "up"}}, Samples: []Sample{{1, 2000}}},
+			}},
+		},
+		{
+			name: "empty label value",
+			body: series(label("job", "")),
+			want: &WriteRequest{Timeseries: []TimeSeries{{Labels: []Label{{"job", ""}}}}},
+		},
+		{
+			name: "unknown fields",
+			body: message(
+				// Metadata, a varint and a fixed32 are skipped.
+				bytesField(3, message(bytesField(2, []byte("temperature")))),
+				protowire.AppendVarint(protowire.AppendTag(nil, 4, protowire.VarintType), 7),
+				series(
+					protowire.AppendFixed32(protowire.AppendTag(nil, 9, protowire.Fixed32Type), 1),
+					label(MetricNameLabel, "up"),
+					bytesField(3, []byte("exemplar")),
+					sample(1, 2000),
+				),
+			),
+			want: &WriteRequest{Timeseries: []TimeSeries{{Labels: []Label{{MetricNameLabel, "up"}}, Samples: []Sample{{1, 2000}}}}},
+		},
+		{
+			name: "wrong wire types",
+			body: message(
+				protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+				series(
+					protowire.AppendVarint(protowire.AppendTag(nil, 2, protowire.VarintType), 1),
+					bytesField(2, message(bytesField(1, []byte("not a double")), protowire.AppendFixed64(protowire.AppendTag(nil, 2, protowire.Fixed64Type), 5))),
+				),
+			),
+			want: &WriteRequest{Timeseries: []TimeSeries{{Samples: []Sample{{}}}}},
+		},
+	}
+	for _, c := range cases {
+		got, err := Decode(snappyLiteral(c.body))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: decoded %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDecodeNonFiniteSamples(t *testing.T) {
+	got, err := Decode(snappyLiteral(series(sample(math.NaN(), 1), sample(math.Inf(-1), 2))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := got.Timeseries[0].Samples
+	if len(samples) != 2 || !math.IsNaN(samples[0].Value) || !math.IsInf(samples[1].Value, -1) {
+		t.Errorf("decoded samples %v", samples)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	full := message(series(label(MetricNameLabel, "up"), sample(1, 2000)))
+	cases := []struct {
+		name string
+		body []byte
+		err  error
+	}{
+		{"not snappy", full, ErrCorruptSnappy},
+		{"truncated snappy", snappyLiteral(full)[:len(full)/2], ErrCorruptSnappy},
+		{"truncated tag", snappyLiteral([]byte{0x80}), ErrInvalidRequest},
+		{"field zero", snappyLiteral([]byte{0x00}), ErrInvalidRequest},
+		{"truncated length", snappyLiteral([]byte{0x0a}), ErrInvalidRequest},
+		{"length past end", snappyLiteral([]byte{0x0a, 0x05, 0x00}), ErrInvalidRequest},
+		{"truncated varint", snappyLiteral([]byte{0x20, 0x80}), ErrInvalidRequest},
+		{"truncated fixed64", snappyLiteral([]byte{0x21, 0x00, 0x00}), ErrInvalidRequest},
+		{"truncated series", snappyLiteral(bytesField(1, []byte{0x0a, 0x04, 0x0a})), ErrInvalidRequest},
+		{"truncated label", snappyLiteral(series(bytesField(1, []byte{0x0a, 0x09, 'a'}))), ErrInvalidRequest},
+		{"truncated sample", snappyLiteral(series(bytesField(2, []byte{0x09, 0x00, 0x00}))), ErrInvalidRequest},
+	}
+	for i := 1; i < len(full); i++ {
+		// Cutting a valid request short anywhere inside its only series
+		// leaves a series that runs past the end.
+		cases = append(cases, struct {
+			name string
+			body []byte
+			err  error
+		}{"cut short", snappyLiteral(full[:i]), ErrInvalidRequest})
+	}
+	for _, c := range cases {
+		if got, err := Decode(c.body); err != c.err {
+			t.Errorf("%s: decoded %+v, %v, want %v", c.name, got, err, c.err)
+		}
+	}
+}
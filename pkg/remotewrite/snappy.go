@@ -0,0 +1,94 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrCorruptSnappy = errors.New("corrupt snappy block")
+
+// maxDecodedLength caps the buffer allocated for a request, the length is
+// taken from the client before any of the block has been checked.
+const maxDecodedLength = 32 * 1024 * 1024
+
+const (
+	tagLiteral = 0x00
+	tagCopy1   = 0x01
+	tagCopy2   = 0x02
+	tagCopy4   = 0x03
+)
+
+// decodeSnappy decodes a snappy block, the framing format is not used by
+// remote write.
+func decodeSnappy(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length > maxDecodedLength {
+		return nil, ErrCorruptSnappy
+	}
+	src = src[n:]
+	dst := make([]byte, 0, length)
+
+	for len(src) > 0 {
+		tag := src[0]
+		var offset, size int
+
+		switch tag & 0x03 {
+		case tagLiteral:
+			size = int(tag >> 2)
+			src = src[1:]
+			if size >= 60 {
+				extra := size - 59
+				if len(src) < extra {
+					return nil, ErrCorruptSnappy
+				}
+				size = 0
+				for i := extra - 1; i >= 0; i-- {
+					size = size<<8 | int(src[i])
+				}
+				src = src[extra:]
+			}
+			size++
+			if size > len(src) || len(dst)+size > int(length) {
+				return nil, ErrCorruptSnappy
+			}
+			dst = append(dst, src[:size]...)
+			src = src[size:]
+			continue
+		case tagCopy1:
+			if len(src) < 2 {
+				return nil, ErrCorruptSnappy
+			}
+			size = 4 + int(tag>>2)&0x07
+			offset = int(tag&0xe0)<<3 | int(src[1])
+			src = src[2:]
+		case tagCopy2:
+			if len(src) < 3 {
+				return nil, ErrCorruptSnappy
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[1:3]))
+			src = src[3:]
+		case tagCopy4:
+			if len(src) < 5 {
+				return nil, ErrCorruptSnappy
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[1:5]))
+			src = src[5:]
+		}
+
+		if offset <= 0 || offset > len(dst) || len(dst)+size > int(length) {
+			return nil, ErrCorruptSnappy
+		}
+		// Copies may overlap the bytes they produce, so go a byte at a time.
+		start := len(dst) - offset
+		for i := 0; i < size; i++ {
+			dst = append(dst, dst[start+i])
+		}
+	}
+
+	if len(dst) != int(length) {
+		return nil, ErrCorruptSnappy
+	}
+	return dst, nil
+}
@@ -0,0 +1,117 @@
+package remotewrite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// snappyLiteral encodes b as a snappy block of literals, which is valid if
+// not compressed.
+func snappyLiteral(b []byte) []byte {
+	dst := uvarint(len(b))
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > 1<<16 {
+			chunk = chunk[:1<<16]
+		}
+		n := len(chunk) - 1
+		switch {
+		case n < 60:
+			dst = append(dst, byte(n)<<2|tagLiteral)
+		case n < 1<<8:
+			dst = append(dst, 60<<2|tagLiteral, byte(n))
+		default:
+			dst = append(dst, 61<<2|tagLiteral, byte(n), byte(n>>8))
+		}
+		dst = append(dst, chunk...)
+		b = b[len(chunk):]
+	}
+	return dst
+}
+
+func uvarint(n int) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, uint64(n))]
+}
+
+func TestDecodeSnappy(t *testing.T) {
+	long := bytes.Repeat([]byte("0123456789"), 7000)
+	cases := []struct {
+		name string
+		src  []byte
+		want []byte
+	}{
+		{"empty", []byte{0x00}, []byte{}},
+		{"short literal", []byte{0x05, 0x10, 'h', 'e', 'l', 'l', 'o'}, []byte("hello")},
+		{"one byte length literal", snappyLiteral(long[:200]), long[:200]},
+		{"two byte length literal", snappyLiteral(long), long},
+		{"four byte length literal", append([]byte{0x03, 63<<2 | tagLiteral, 0x02, 0x00, 0x00, 0x00}, "abc"...), []byte("abc")},
+		// "abcd" then copy 8 bytes from 4 back, overlapping what it writes.
+		{"copy1 overlapping", []byte{0x0c, 0x0c, 'a', 'b', 'c', 'd', (8-4)<<2 | tagCopy1, 0x04}, []byte("abcdabcdabcd")},
+		{"copy2", []byte{0x07, 0x08, 'x', 'y', 'z', (4-1)<<2 | tagCopy2, 0x03, 0x00}, []byte("xyzxyzx")},
+		{"copy4", []byte{0x05, 0x04, 'x', 'y', (3-1)<<2 | tagCopy4, 0x02, 0x00, 0x00, 0x00}, []byte("xyxyx")},
+	}
+	for _, c := range cases {
+		got, err := decodeSnappy(c.src)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !bytes.Equal(got, c.want) {
+			t.Errorf("%s: decoded %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+// A copy1 offset takes bits 8 to 10 from the top of its tag.
+func TestDecodeSnappyCopy1Offset(t *testing.T) {
+	literal := make([]byte, 300)
+	for i := range literal {
+		literal[i] = byte(i)
+	}
+	src := uvarint(len(literal) + 11)
+	src = append(src, snappyLiteral(literal)[len(uvarint(len(literal))):]...)
+	// Copy 11 bytes from 0x12b, 299, bytes back.
+	src = append(src, 0x01<<5|(11-4)<<2|tagCopy1, 0x2b)
+
+	got, err := decodeSnappy(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte{}, literal...), literal[1:12]...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("decoded tail %v, want %v", got[len(literal):], want[len(literal):])
+	}
+}
+
+func TestDecodeSnappyCorrupt(t *testing.T) {
+	cases := []struct {
+		name string
+		src  []byte
+	}{
+		{"nil", nil},
+		{"truncated length", []byte{0x80}},
+		{"length too long", []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+		{"length over cap", uvarint(maxDecodedLength + 1)},
+		{"missing data", []byte{0x05}},
+		{"short data", []byte{0x05, 0x10, 'h', 'e', 'l'}},
+		{"extra data", []byte{0x02, 0x08, 'a', 'b', 'c'}},
+		{"literal past length", []byte{0x02, 0x10, 'h', 'e', 'l', 'l', 'o'}},
+		{"truncated literal length", []byte{0x05, 61 << 2}},
+		{"truncated four byte literal length", []byte{0x05, 63<<2 | tagLiteral, 0x04, 0x00}},
+		{"truncated copy1", []byte{0x05, 0x00, 'a', tagCopy1}},
+		{"truncated copy2", []byte{0x05, 0x00, 'a', tagCopy2, 0x01}},
+		{"truncated copy4", []byte{0x05, 0x00, 'a', tagCopy4, 0x01, 0x00, 0x00}},
+		{"copy before data", []byte{0x04, tagCopy1, 0x01}},
+		{"zero offset", []byte{0x05, 0x00, 'a', tagCopy1, 0x00}},
+		{"offset past start", []byte{0x05, 0x00, 'a', tagCopy2, 0x02, 0x00}},
+		{"huge copy4 offset", []byte{0x05, 0x00, 'a', tagCopy4, 0xff, 0xff, 0xff, 0xff}},
+		{"copy past length", []byte{0x03, 0x00, 'a', (4-4)<<2 | tagCopy1, 0x01}},
+	}
+	for _, c := range cases {
+		if got, err := decodeSnappy(c.src); err != ErrCorruptSnappy {
+			t.Errorf("%s: decoded %q, %v, want %v", c.name, got, err, ErrCorruptSnappy)
+		}
+	}
+}
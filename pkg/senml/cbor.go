@@ -0,0 +1,485 @@
+package senml
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+var ErrInvalidCBOR = errors.New("senml: invalid cbor")
+
+// CBOR map keys of each field, section 6 of RFC 8428.
+const (
+	labelBaseVersion = -1
+	labelBaseName    = -2
+	labelBaseTime    = -3
+	labelBaseUnit    = -4
+	labelBaseValue   = -5
+	labelBaseSum     = -6
+	labelName        = 0
+	labelUnit        = 1
+	labelValue       = 2
+	labelStringValue = 3
+	labelBoolValue   = 4
+	labelSum         = 5
+	labelTime        = 6
+	labelUpdateTime  = 7
+	labelDataValue   = 8
+)
+
+// CBOR major types.
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// maxCBORDepth bounds nesting, a SenML pack is an array of flat maps.
+const maxCBORDepth = 16
+
+// DecodeCBOR reads a CBOR pack from r.
+func DecodeCBOR(r io.Reader) (Pack, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &cborDecoder{b: b}
+	v, err := d.value(0)
+	if err == errBreak {
+		return nil, ErrInvalidCBOR
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(d.b) != 0 {
+		return nil, ErrInvalidCBOR
+	}
+
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, ErrInvalidCBOR
+	}
+	pack := make(Pack, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return nil, ErrInvalidCBOR
+		}
+		record, err := cborRecord(fields)
+		if err != nil {
+			return nil, err
+		}
+		pack = append(pack, *record)
+	}
+	return pack, nil
+}
+
+func cborRecord(fields map[interface{}]interface{}) (*Record, error) {
+	r := &Record{}
+	for k, v := range fields {
+		label, ok := k.(int64)
+		if !ok {
+			// Labels ending in an underscore must be understood, others may
+			// be ignored.
+			if s, ok := k.(string); ok && len(s) > 0 && s[len(s)-1] == '_' {
+				return nil, fmt.Errorf("senml: unsupported field %s", s)
+			}
+			continue
+		}
+
+		var err error
+		switch label {
+		case labelBaseVersion:
+			var f float64
+			f, err = cborNumber(v)
+			r.BaseVersion = int(f)
+		case labelBaseName:
+			r.BaseName, err = cborText(v)
+		case labelBaseTime:
+			r.BaseTime, err = cborNumber(v)
+		case labelBaseUnit:
+			r.BaseUnit, err = cborText(v)
+		case labelBaseValue:
+			r.BaseValue, err = cborNumber(v)
+		case labelBaseSum:
+			r.BaseSum, err = cborNumber(v)
+		case labelName:
+			r.Name, err = cborText(v)
+		case labelUnit:
+			r.Unit, err = cborText(v)
+		case labelValue:
+			var f float64
+			f, err = cborNumber(v)
+			r.Value = &f
+		case labelStringValue:
+			var s string
+			s, err = cborText(v)
+			r.StringValue = &s
+		case labelBoolValue:
+			b, ok := v.(bool)
+			if !ok {
+				err = ErrInvalidCBOR
+			}
+			r.BoolValue = &b
+		case labelSum:
+			var f float64
+			f, err = cborNumber(v)
+			r.Sum = &f
+		case labelTime:
+			r.Time, err = cborNumber(v)
+		case labelUpdateTime:
+			r.UpdateTime, err = cborNumber(v)
+		case labelDataValue:
+			// Data values are byte strings in CBOR and base64 in JSON, they
+			// aren't stored so the raw bytes are kept.
+			b, ok := v.([]byte)
+			if !ok {
+				err = ErrInvalidCBOR
+			}
+			s := string(b)
+			r.DataValue = &s
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func cborNumber(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	}
+	return 0, ErrInvalidCBOR
+}
+
+func cborText(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", ErrInvalidCBOR
+	}
+	return s, nil
+}
+
+type cborDecoder struct {
+	b []byte
+}
+
+// errBreak is returned for the break code that ends an indefinite length
+// array or map.
+var errBreak = errors.New("senml: cbor break")
+
+// value decodes the next item. Integers decode to int64 when they fit, or
+// uint64 otherwise, and maps to map[interface{}]interface{}.
+func (d *cborDecoder) value(depth int) (interface{}, error) {
+	if depth > maxCBORDepth || len(d.b) == 0 {
+		return nil, ErrInvalidCBOR
+	}
+	initial := d.b[0]
+	major, info := initial>>5, initial&0x1f
+	d.b = d.b[1:]
+
+	if major == majorSimple {
+		return d.simple(info)
+	}
+
+	// Only strings, arrays and maps may have an indefinite length, and
+	// strings aren't supported.
+	indefinite := info == 31
+	if indefinite && major != majorArray && major != majorMap {
+		return nil, ErrInvalidCBOR
+	}
+	var arg uint64
+	if !indefinite {
+		var err error
+		if arg, err = d.argument(info); err != nil {
+			return nil, err
+		}
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, ErrInvalidCBOR
+		}
+		return -1 - int64(arg), nil
+	case majorBytes, majorText:
+		if arg > uint64(len(d.b)) {
+			return nil, ErrInvalidCBOR
+		}
+		s := d.b[:arg]
+		d.b = d.b[arg:]
+		if major == majorText {
+			return string(s), nil
+		}
+		return append([]byte(nil), s...), nil
+	case majorArray:
+		var items []interface{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			item, err := d.value(depth + 1)
+			if err == errBreak && indefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case majorMap:
+		items := map[interface{}]interface{}{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			key, err := d.value(depth + 1)
+			if err == errBreak && indefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, uint64, string:
+			default:
+				return nil, ErrInvalidCBOR
+			}
+			value, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items[key] = value
+		}
+		return items, nil
+	case majorTag:
+		// Tags such as self-described CBOR don't change the meaning of a
+		// pack, so the tagged item is returned as is.
+		return d.value(depth + 1)
+	}
+	return nil, ErrInvalidCBOR
+}
+
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+	size := 0
+	switch info {
+	case 24:
+		size = 1
+	case 25:
+		size = 2
+	case 26:
+		size = 4
+	case 27:
+		size = 8
+	default:
+		return 0, ErrInvalidCBOR
+	}
+	if len(d.b) < size {
+		return 0, ErrInvalidCBOR
+	}
+	var arg uint64
+	for _, c := range d.b[:size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.b = d.b[size:]
+	return arg, nil
+}
+
+func (d *cborDecoder) simple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 31:
+		return nil, errBreak
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+	switch info {
+	case 25:
+		return float16(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+	return nil, ErrInvalidCBOR
+}
+
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// EncodeCBOR writes pack to w as CBOR.
+func EncodeCBOR(w io.Writer, pack Pack) error {
+	e := &cborEncoder{}
+	e.head(majorArray, uint64(len(pack)))
+	for _, r := range pack {
+		e.record(&r)
+	}
+	_, err := w.Write(e.b)
+	return err
+}
+
+type cborEncoder struct {
+	b []byte
+}
+
+func (e *cborEncoder) record(r *Record) {
+	type field struct {
+		label int64
+		write func()
+	}
+	var fields []field
+	text := func(label int64, s string) {
+		if s != "" {
+			fields = append(fields, field{label, func() { e.text(s) }})
+		}
+	}
+	number := func(label int64, f float64) {
+		fields = append(fields, field{label, func() { e.float(f) }})
+	}
+
+	if r.BaseVersion != 0 {
+		number(labelBaseVersion, float64(r.BaseVersion))
+	}
+	text(labelBaseName, r.BaseName)
+	if r.BaseTime != 0 {
+		number(labelBaseTime, r.BaseTime)
+	}
+	text(labelBaseUnit, r.BaseUnit)
+	if r.BaseValue != 0 {
+		number(labelBaseValue, r.BaseValue)
+	}
+	if r.BaseSum != 0 {
+		number(labelBaseSum, r.BaseSum)
+	}
+	text(labelName, r.Name)
+	text(labelUnit, r.Unit)
+	if r.Value != nil {
+		number(labelValue, *r.Value)
+	}
+	if r.StringValue != nil {
+		s := *r.StringValue
+		fields = append(fields, field{labelStringValue, func() { e.text(s) }})
+	}
+	if r.BoolValue != nil {
+		b := *r.BoolValue
+		fields = append(fields, field{labelBoolValue, func() { e.bool(b) }})
+	}
+	if r.Sum != nil {
+		number(labelSum, *r.Sum)
+	}
+	if r.Time != 0 {
+		number(labelTime, r.Time)
+	}
+	if r.UpdateTime != 0 {
+		number(labelUpdateTime, r.UpdateTime)
+	}
+	if r.DataValue != nil {
+		s := *r.DataValue
+		fields = append(fields, field{labelDataValue, func() {
+			e.head(majorBytes, uint64(len(s)))
+			e.b = append(e.b, s...)
+		}})
+	}
+
+	e.head(majorMap, uint64(len(fields)))
+	for _, f := range fields {
+		e.int(f.label)
+		f.write()
+	}
+}
+
+func (e *cborEncoder) head(major byte, arg uint64) {
+	major <<= 5
+	switch {
+	case arg < 24:
+		e.b = append(e.b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		e.b = append(e.b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		e.b = append(e.b, major|25)
+		e.b = binary.BigEndian.AppendUint16(e.b, uint16(arg))
+	case arg <= math.MaxUint32:
+		e.b = append(e.b, major|26)
+		e.b = binary.BigEndian.AppendUint32(e.b, uint32(arg))
+	default:
+		e.b = append(e.b, major|27)
+		e.b = binary.BigEndian.AppendUint64(e.b, arg)
+	}
+}
+
+func (e *cborEncoder) int(i int64) {
+	if i < 0 {
+		e.head(majorNegative, uint64(-1-i))
+		return
+	}
+	e.head(majorUnsigned, uint64(i))
+}
+
+func (e *cborEncoder) text(s string) {
+	e.head(majorText, uint64(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *cborEncoder) bool(b bool) {
+	if b {
+		e.b = append(e.b, majorSimple<<5|21)
+		return
+	}
+	e.b = append(e.b, majorSimple<<5|20)
+}
+
+// Whole numbers that fit are written as integers, as RFC 8428 recommends,
+// everything else as a double.
+func (e *cborEncoder) float(f float64) {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		e.int(int64(f))
+		return
+	}
+	e.b = append(e.b, majorSimple<<5|27)
+	e.b = binary.BigEndian.AppendUint64(e.b, math.Float64bits(f))
+}
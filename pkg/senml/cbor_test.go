@@ -0,0 +1,186 @@
+package senml
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func text(s string) *string { return &s }
+func boolean(b bool) *bool  { return &b }
+
+func TestDecodeCBOR(t *testing.T) {
+	cases := []struct {
+		name string
+		src  []byte
+		want Pack
+	}{
+		{"empty pack", []byte{0x80}, Pack{}},
+		{
+			// RFC 8428 section 6 example, [{-2: "urn:dev:ow:10e2073a01080063", -3: 1320067464, 0: "voltage", 1: "V", 2: 120.1}]
+			name: "rfc example",
+			src: append(append([]byte{0x81, 0xa5, 0x21, 0x78, 0x1b}, "urn:dev:ow:10e2073a01080063"...),
+				append([]byte{0x22, 0x1a, 0x4e, 0xae, 0xa1, 0x88, 0x00, 0x67}, append([]byte("voltage"),
+					0x01, 0x61, 'V', 0x02, 0xfb, 0x40, 0x5e, 0x06, 0x66, 0x66, 0x66, 0x66, 0x66)...)...),
+			want: Pack{{BaseName: "urn:dev:ow:10e2073a01080063", BaseTime: 1320067464, Name: "voltage", Unit: "V", Value: float(120.1)}},
+		},
+		{
+			name: "every value type",
+			src: []byte{0x84,
+				0xa1, 0x02, 0xf9, 0x3e, 0x00, // half 1.5
+				0xa1, 0x03, 0x62, 'o', 'k',
+				0xa1, 0x04, 0xf5,
+				0xa1, 0x08, 0x42, 0x01, 0x02,
+			},
+			want: Pack{{Value: float(1.5)}, {StringValue: text("ok")}, {BoolValue: boolean(true)}, {DataValue: text("\x01\x02")}},
+		},
+		{
+			name: "numbers",
+			src: []byte{0x85,
+				0xa1, 0x02, 0x20, // -1
+				0xa1, 0x02, 0x3b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // min int64
+				0xa1, 0x02, 0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // max uint64
+				0xa1, 0x02, 0xfa, 0x3f, 0xc0, 0x00, 0x00, // single 1.5
+				0xa1, 0x02, 0xf9, 0x00, 0x01, // smallest half subnormal
+			},
+			want: Pack{{Value: float(-1)}, {Value: float(math.MinInt64)}, {Value: float(math.MaxUint64)}, {Value: float(1.5)}, {Value: float(math.Ldexp(1, -24))}},
+		},
+		{
+			name: "indefinite lengths and tags",
+			// Self-described CBOR tag 55799, then [_ {_ 0: "a", 2: 1}].
+			src:  []byte{0xd9, 0xd9, 0xf7, 0x9f, 0xbf, 0x00, 0x61, 'a', 0x02, 0x01, 0xff, 0xff},
+			want: Pack{{Name: "a", Value: float(1)}},
+		},
+		{
+			name: "unknown fields",
+			// {"x": 1, 99: null, 0: "a"}, labels not ending in _ may be ignored.
+			src:  []byte{0x81, 0xa3, 0x61, 'x', 0x01, 0x18, 0x63, 0xf6, 0x00, 0x61, 'a'},
+			want: Pack{{Name: "a"}},
+		},
+	}
+	for _, c := range cases {
+		got, err := DecodeCBOR(bytes.NewReader(c.src))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: decoded %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDecodeCBORInvalid(t *testing.T) {
+	nested := bytes.Repeat([]byte{0x81}, maxCBORDepth+2)
+	cases := []struct {
+		name string
+		src  []byte
+	}{
+		{"empty", nil},
+		{"not an array", []byte{0xa0}},
+		{"record not a map", []byte{0x81, 0x01}},
+		{"trailing bytes", []byte{0x80, 0x00}},
+		{"truncated array", []byte{0x82, 0xa0}},
+		{"truncated map", []byte{0x81, 0xa2, 0x00, 0x61, 'a'}},
+		{"map missing value", []byte{0x81, 0xa1, 0x00}},
+		{"truncated argument", []byte{0x99, 0x01}},
+		{"truncated text", []byte{0x81, 0xa1, 0x00, 0x65, 'a', 'b'}},
+		{"huge text length", []byte{0x81, 0xa1, 0x00, 0x7b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"truncated float", []byte{0x81, 0xa1, 0x02, 0xfb, 0x40, 0x5e}},
+		{"reserved argument", []byte{0x1c}},
+		{"indefinite integer", []byte{0x1f}},
+		{"indefinite negative", []byte{0x81, 0xa1, 0x02, 0x3f}},
+		{"indefinite text", []byte{0x81, 0xa1, 0x00, 0x7f, 0x61, 'a', 0xff}},
+		{"indefinite tag", []byte{0xdf, 0x80}},
+		{"unterminated indefinite array", []byte{0x9f, 0xa0}},
+		{"break at top", []byte{0xff}},
+		{"break in definite array", []byte{0x82, 0xa0, 0xff}},
+		{"break as map value", []byte{0x9f, 0xbf, 0x00, 0xff, 0xff, 0xff}},
+		{"negative out of range", []byte{0x81, 0xa1, 0x02, 0x3b, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"array key", []byte{0x81, 0xa1, 0x80, 0x00}},
+		{"bytes key", []byte{0x81, 0xa1, 0x41, 0x00, 0x00}},
+		{"simple value", []byte{0x81, 0xa1, 0x02, 0xf8, 0x20}},
+		{"too deep", nested},
+		{"text value", []byte{0x81, 0xa1, 0x02, 0x61, '1'}},
+		{"number name", []byte{0x81, 0xa1, 0x00, 0x01}},
+		{"string bool", []byte{0x81, 0xa1, 0x04, 0x61, 't'}},
+		{"text data", []byte{0x81, 0xa1, 0x08, 0x61, 'd'}},
+	}
+	for _, c := range cases {
+		if got, err := DecodeCBOR(bytes.NewReader(c.src)); err != ErrInvalidCBOR {
+			t.Errorf("%s: decoded %+v, %v", c.name, got, err)
+		}
+	}
+
+	// Labels ending in an underscore must be understood.
+	if got, err := DecodeCBOR(bytes.NewReader([]byte{0x81, 0xa1, 0x62, 'x', '_', 0x01})); err == nil {
+		t.Errorf("decoded %+v with an unknown required field", got)
+	}
+}
+
+// Every prefix of a valid pack is truncated somewhere.
+func TestDecodeCBORTruncated(t *testing.T) {
+	var b bytes.Buffer
+	pack := Pack{
+		{BaseName: "boiler/", BaseTime: 1.5e9, BaseUnit: "Cel", Name: "temp", Value: float(21.25), Time: -5},
+		{Name: "on", BoolValue: boolean(true), UpdateTime: 60},
+		{Name: "state", StringValue: text("ok"), DataValue: text("\x00\xff")},
+	}
+	if err := EncodeCBOR(&b, pack); err != nil {
+		t.Fatal(err)
+	}
+	full := b.Bytes()
+	for i := 0; i < len(full); i++ {
+		if got, err := DecodeCBOR(bytes.NewReader(full[:i])); err != ErrInvalidCBOR {
+			t.Errorf("%d of %d bytes: decoded %+v, %v", i, len(full), got, err)
+		}
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	packs := []Pack{
+		{},
+		{{BaseName: "boiler/", BaseTime: 1.5e9, BaseUnit: "Cel", BaseValue: 20, BaseSum: 1, BaseVersion: 10, Name: "temp", Unit: "Cel", Value: float(21.25), Sum: float(3), Time: -5, UpdateTime: 60}},
+		{{Name: "big", Value: float(1 << 60)}, {Name: "neg", Value: float(-1e300)}, {Name: "tiny", Value: float(5e-324)}, {Name: "zero", Value: float(0)}},
+		{{Name: "on", BoolValue: boolean(false)}, {Name: "s", StringValue: text("")}, {Name: "d", DataValue: text(string(make([]byte, 300)))}},
+	}
+	for _, pack := range packs {
+		var b bytes.Buffer
+		if err := EncodeCBOR(&b, pack); err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeCBOR(&b)
+		if err != nil {
+			t.Errorf("%+v: %v", pack, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, pack) {
+			t.Errorf("round trip of %+v gave %+v", pack, got)
+		}
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	cases := []struct {
+		bits uint16
+		want float64
+	}{
+		{0x0000, 0},
+		{0x3c00, 1},
+		{0xc000, -2},
+		{0x7bff, 65504},
+		{0x0400, math.Ldexp(1, -14)},
+		{0x03ff, math.Ldexp(1023, -24)},
+		{0x7c00, math.Inf(1)},
+		{0xfc00, math.Inf(-1)},
+	}
+	for _, c := range cases {
+		if got := float16(c.bits); got != c.want {
+			t.Errorf("float16(%#04x) = %v, want %v", c.bits, got, c.want)
+		}
+	}
+	if got := float16(0x7e00); !math.IsNaN(got) {
+		t.Errorf("float16(0x7e00) = %v, want NaN", got)
+	}
+}
@@ -0,0 +1,20 @@
+package senml
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// DecodeJSON reads a JSON pack from r.
+func DecodeJSON(r io.Reader) (Pack, error) {
+	var pack Pack
+	if err := json.NewDecoder(r).Decode(&pack); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// EncodeJSON writes pack to w as JSON.
+func EncodeJSON(w io.Writer, pack Pack) error {
+	return json.NewEncoder(w).Encode(pack)
+}
@@ -0,0 +1,157 @@
+// Package senml encodes and decodes Sensor Measurement Lists (RFC 8428) in
+// their JSON and CBOR representations.
+package senml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	ContentTypeJSON = "application/senml+json"
+	ContentTypeCBOR = "application/senml+cbor"
+)
+
+var (
+	ErrEmptyPack = errors.New("senml: empty pack")
+	ErrVersion   = errors.New("senml: unsupported version")
+)
+
+// Version is the highest base version understood.
+const Version = 10
+
+// relativeTimeLimit is 2**28 seconds, resolved times below it are relative
+// to the time the pack was received.
+const relativeTimeLimit = 1 << 28
+
+// Record is a single SenML record. Values are pointers so a record can tell a
+// zero value from a missing one.
+type Record struct {
+	BaseName    string  `json:"bn,omitempty"`
+	BaseTime    float64 `json:"bt,omitempty"`
+	BaseUnit    string  `json:"bu,omitempty"`
+	BaseValue   float64 `json:"bv,omitempty"`
+	BaseSum     float64 `json:"bs,omitempty"`
+	BaseVersion int     `json:"bver,omitempty"`
+
+	Name        string   `json:"n,omitempty"`
+	Unit        string   `json:"u,omitempty"`
+	Value       *float64 `json:"v,omitempty"`
+	StringValue *string  `json:"vs,omitempty"`
+	BoolValue   *bool    `json:"vb,omitempty"`
+	DataValue   *string  `json:"vd,omitempty"`
+	Sum         *float64 `json:"s,omitempty"`
+	Time        float64  `json:"t,omitempty"`
+	UpdateTime  float64  `json:"ut,omitempty"`
+}
+
+type Pack []Record
+
+// Resolved is a record with the base fields applied, holding a numeric
+// value. Boolean values resolve to 1 and 0. BaseName is the base name in
+// effect, the prefix of Name.
+type Resolved struct {
+	BaseName string
+	Name     string
+	Unit     string
+	Value    float64
+	Time     time.Time
+}
+
+// Resolve applies the base fields of a pack to its records, as in section 4.6
+// of RFC 8428. Records must carry a numeric or boolean value, relative times
+// are resolved against now.
+func Resolve(pack Pack, now time.Time) ([]Resolved, error) {
+	if len(pack) == 0 {
+		return nil, ErrEmptyPack
+	}
+
+	var base Record
+	resolved := make([]Resolved, 0, len(pack))
+	for i, r := range pack {
+		if r.BaseVersion > Version {
+			return nil, ErrVersion
+		}
+		if r.BaseName != "" {
+			base.BaseName = r.BaseName
+		}
+		if r.BaseTime != 0 {
+			base.BaseTime = r.BaseTime
+		}
+		if r.BaseUnit != "" {
+			base.BaseUnit = r.BaseUnit
+		}
+		if r.BaseValue != 0 {
+			base.BaseValue = r.BaseValue
+		}
+
+		name := base.BaseName + r.Name
+		if !validName(name) {
+			return nil, fmt.Errorf("senml: record %d has an invalid name %q", i, name)
+		}
+
+		unit := r.Unit
+		if unit == "" {
+			unit = base.BaseUnit
+		}
+		if !ValidUnit(unit) {
+			return nil, fmt.Errorf("senml: record %d has an unregistered unit %q", i, unit)
+		}
+
+		var value float64
+		switch {
+		case r.Value != nil:
+			value = base.BaseValue + *r.Value
+		case r.BoolValue != nil && *r.BoolValue:
+			value = 1
+		case r.BoolValue != nil:
+			value = 0
+		default:
+			return nil, fmt.Errorf("senml: record %d has no numeric value", i)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("senml: record %d has an invalid value", i)
+		}
+
+		resolved = append(resolved, Resolved{
+			BaseName: base.BaseName,
+			Name:     name,
+			Unit:     unit,
+			Value:    value,
+			Time:     resolveTime(base.BaseTime+r.Time, now),
+		})
+	}
+	return resolved, nil
+}
+
+func resolveTime(t float64, now time.Time) time.Time {
+	if t < relativeTimeLimit {
+		return now.Add(time.Duration(t * float64(time.Second))).UTC()
+	}
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC()
+}
+
+// Time converts t to SenML seconds since the Unix epoch.
+func Time(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// Names must start with a letter or digit, and may hold letters, digits and
+// the characters - : . / _
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case i > 0 && (c == '-' || c == ':' || c == '.' || c == '/' || c == '_'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package senml
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func float(f float64) *float64 { return &f }
+
+func TestResolveUnits(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		pack Pack
+		want []string
+		err  string
+	}{
+		{
+			name: "registered",
+			pack: Pack{{BaseName: "boiler/", Name: "temp", Unit: "Cel", Value: float(21)}, {Name: "rh", Unit: "%RH", Value: float(40)}},
+			want: []string{"Cel", "%RH"},
+		},
+		{
+			name: "base unit",
+			pack: Pack{{BaseName: "boiler/", BaseUnit: "Cel", Name: "in", Value: float(21)}, {Name: "out", Value: float(60)}, {Name: "flow", Unit: "l/s", Value: float(2)}},
+			want: []string{"Cel", "Cel", "l/s"},
+		},
+		{
+			name: "unitless",
+			pack: Pack{{Name: "count", Value: float(3)}},
+			want: []string{""},
+		},
+		{
+			name: "unregistered",
+			pack: Pack{{Name: "temp", Unit: "Cel", Value: float(21)}, {Name: "temp", Unit: "celsius", Value: float(21)}},
+			err:  `record 1 has an unregistered unit "celsius"`,
+		},
+		{
+			name: "unregistered base unit",
+			pack: Pack{{BaseUnit: "degC", Name: "temp", Value: float(21)}},
+			err:  `record 0 has an unregistered unit "degC"`,
+		},
+		{
+			name: "case matters",
+			pack: Pack{{Name: "temp", Unit: "cel", Value: float(21)}},
+			err:  `unregistered unit "cel"`,
+		},
+	}
+	for _, c := range cases {
+		resolved, err := Resolve(c.pack, now)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: error = %v, want %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		for i, r := range resolved {
+			if r.Unit != c.want[i] {
+				t.Errorf("%s: record %d unit %q, want %q", c.name, i, r.Unit, c.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package senml
+
+// Units is the SenML units registry, the units of RFC 8428 section 12.1 and
+// the secondary units of RFC 8798, keyed by symbol.
+var Units = map[string]string{
+	"m":        "meter",
+	"kg":       "kilogram",
+	"g":        "gram",
+	"s":        "second",
+	"A":        "ampere",
+	"K":        "kelvin",
+	"cd":       "candela",
+	"mol":      "mole",
+	"Hz":       "hertz",
+	"rad":      "radian",
+	"sr":       "steradian",
+	"N":        "newton",
+	"Pa":       "pascal",
+	"J":        "joule",
+	"W":        "watt",
+	"C":        "coulomb",
+	"V":        "volt",
+	"F":        "farad",
+	"Ohm":      "ohm",
+	"S":        "siemens",
+	"Wb":       "weber",
+	"T":        "tesla",
+	"H":        "henry",
+	"Cel":      "degrees Celsius",
+	"lm":       "lumen",
+	"lx":       "lux",
+	"Bq":       "becquerel",
+	"Gy":       "gray",
+	"Sv":       "sievert",
+	"kat":      "katal",
+	"m2":       "square meter (area)",
+	"m3":       "cubic meter (volume)",
+	"l":        "liter (volume)",
+	"m/s":      "meter per second (velocity)",
+	"m/s2":     "meter per square second (acceleration)",
+	"m3/s":     "cubic meter per second (flow rate)",
+	"l/s":      "liter per second (flow rate)",
+	"W/m2":     "watt per square meter (irradiance)",
+	"cd/m2":    "candela per square meter (luminance)",
+	"bit":      "bit (information content)",
+	"bit/s":    "bit per second (data rate)",
+	"lat":      "degrees latitude",
+	"lon":      "degrees longitude",
+	"pH":       "pH value (acidity; logarithmic quantity)",
+	"dB":       "decibel (logarithmic quantity)",
+	"dBW":      "decibel relative to 1 W (power level)",
+	"Bspl":     "bel (sound pressure level; logarithmic quantity)",
+	"count":    "1 (counter value)",
+	"/":        "1 (ratio e.g., value of a switch)",
+	"%":        "1 (ratio e.g., value of a switch)",
+	"%RH":      "percentage (relative humidity)",
+	"%EL":      "percentage (remaining battery energy level)",
+	"EL":       "seconds (remaining battery energy level)",
+	"1/s":      "1 per second (event rate)",
+	"1/min":    "1 per minute (event rate)",
+	"beat/min": "1 per minute (heart rate in beats per minute)",
+	"beats":    "1 (cumulative number of heart beats)",
+	"S/m":      "siemens per meter (conductivity)",
+	"B":        "byte (information content)",
+	"VA":       "volt-ampere (apparent power)",
+	"VAs":      "volt-ampere second (apparent energy)",
+	"var":      "volt-ampere reactive (reactive power)",
+	"vars":     "volt-ampere-reactive second (reactive energy)",
+	"J/m":      "joule per meter (energy per distance)",
+	"kg/m3":    "kilogram per cubic meter (mass density, mass concentration)",
+	"deg":      "degree (angle)",
+	"NTU":      "nephelometric turbidity unit",
+	"ms":       "millisecond",
+	"min":      "minute",
+	"h":        "hour",
+	"MHz":      "megahertz",
+	"kW":       "kilowatt",
+	"kVA":      "kilovolt-ampere",
+	"kvar":     "kilovar",
+	"Ah":       "ampere-hour",
+	"Wh":       "watt-hour",
+	"kWh":      "kilowatt-hour",
+	"varh":     "var-hour",
+	"kvarh":    "kilovar-hour",
+	"kVAh":     "kilovolt-ampere-hour",
+	"Wh/km":    "watt-hour per kilometer",
+	"KiB":      "kibibyte",
+	"GB":       "gigabyte",
+	"Mbit/s":   "megabit per second",
+	"B/s":      "byte per second",
+	"MB/s":     "megabyte per second",
+	"mV":       "millivolt",
+	"mA":       "milliampere",
+	"dBm":      "decibel (milliwatt)",
+	"ug/m3":    "microgram per cubic meter",
+	"mm/h":     "millimeter per hour",
+	"m/h":      "meter per hour",
+	"ppm":      "parts per million",
+	"/100":     "percent",
+	"/1000":    "permille",
+	"hPa":      "hectopascal",
+	"mm":       "millimeter",
+	"cm":       "centimeter",
+	"km":       "kilometer",
+	"km/h":     "kilometer per hour",
+}
+
+// ValidUnit reports whether unit is in the registry, the empty unit is valid
+// and means the value is unitless or the unit is unknown.
+func ValidUnit(unit string) bool {
+	if unit == "" {
+		return true
+	}
+	_, ok := Units[unit]
+	return ok
+}